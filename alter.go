@@ -0,0 +1,221 @@
+package aspect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// alterAction compiles a single action of an ALTER TABLE statement
+type alterAction func(d Dialect) (string, error)
+
+// ColumnAlteration is a change to an existing column that not every
+// dialect can perform with ALTER TABLE
+type ColumnAlteration string
+
+const (
+	AlterType    ColumnAlteration = "type"
+	AlterNotNull ColumnAlteration = "NOT NULL constraint"
+	AlterDefault ColumnAlteration = "DEFAULT"
+)
+
+// ColumnAlterer is an optional interface for dialects that cannot perform
+// every ColumnAlteration, such as sqlite3, which can only add and drop
+// columns. Dialects that do not implement it are assumed to support all
+// of them.
+type ColumnAlterer interface {
+	CanAlterColumn(ColumnAlteration) bool
+}
+
+// canAlterColumn returns an error if the dialect cannot perform the given
+// alteration of the named column
+func canAlterColumn(d Dialect, alteration ColumnAlteration, name string) error {
+	alterer, ok := d.(ColumnAlterer)
+	if !ok || alterer.CanAlterColumn(alteration) {
+		return nil
+	}
+	return fmt.Errorf(
+		"aspect: the dialect %T cannot alter the %s of column %s - the table must be rebuilt",
+		d, alteration, name,
+	)
+}
+
+// AlterTableStmt is the internal representation of an ALTER TABLE statement.
+// Multiple actions will be joined by commas, but some dialects, such as
+// sqlite3, only support a single action per statement.
+type AlterTableStmt struct {
+	Stmt
+	table   *TableElem
+	actions []alterAction
+}
+
+// AddColumn adds the ADD COLUMN action for the given column. Columns that
+// are foreign keys of the statement's table will include their REFERENCES.
+func (stmt AlterTableStmt) AddColumn(column ColumnElem) AlterTableStmt {
+	var create Creatable = column
+	if stmt.table != nil {
+		for _, fk := range stmt.table.fks {
+			if fk.name == column.Name() {
+				create = fk
+				break
+			}
+		}
+	}
+	return stmt.add(func(d Dialect) (string, error) {
+		compiled, err := create.Create(d)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ADD COLUMN %s", compiled), nil
+	})
+}
+
+// DropColumn adds the DROP COLUMN action for the column with the given name.
+func (stmt AlterTableStmt) DropColumn(name string) AlterTableStmt {
+	return stmt.add(func(d Dialect) (string, error) {
+		return fmt.Sprintf(`DROP COLUMN "%s"`, name), nil
+	})
+}
+
+// AlterColumnType changes the type of the column with the given name. Only
+// the SQL type is used - constraints such as NOT NULL are ignored.
+func (stmt AlterTableStmt) AlterColumnType(name string, typ Type) AlterTableStmt {
+	return stmt.add(func(d Dialect) (string, error) {
+		if err := canAlterColumn(d, AlterType, name); err != nil {
+			return "", err
+		}
+		base, _, _, err := describeType(d, typ)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`ALTER COLUMN "%s" TYPE %s`, name, base), nil
+	})
+}
+
+// SetNotNull adds the NOT NULL constraint to the column with the given name.
+func (stmt AlterTableStmt) SetNotNull(name string) AlterTableStmt {
+	return stmt.add(func(d Dialect) (string, error) {
+		if err := canAlterColumn(d, AlterNotNull, name); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`ALTER COLUMN "%s" SET NOT NULL`, name), nil
+	})
+}
+
+// DropNotNull removes the NOT NULL constraint from the column with the
+// given name.
+func (stmt AlterTableStmt) DropNotNull(name string) AlterTableStmt {
+	return stmt.add(func(d Dialect) (string, error) {
+		if err := canAlterColumn(d, AlterNotNull, name); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`ALTER COLUMN "%s" DROP NOT NULL`, name), nil
+	})
+}
+
+// SetDefault sets the DEFAULT of the column with the given name. The value
+// may be a literal or a Clause, as with the Default field of types.
+func (stmt AlterTableStmt) SetDefault(name string, value interface{}) AlterTableStmt {
+	return stmt.add(func(d Dialect) (string, error) {
+		if err := canAlterColumn(d, AlterDefault, name); err != nil {
+			return "", err
+		}
+		def, err := CompileDefault(d, value)
+		if err != nil {
+			return "", err
+		}
+		if def == "" {
+			return "", fmt.Errorf(
+				"aspect: cannot SET DEFAULT of column %s to a nil value", name,
+			)
+		}
+		return fmt.Sprintf(`ALTER COLUMN "%s" SET %s`, name, def), nil
+	})
+}
+
+// DropDefault removes the DEFAULT of the column with the given name.
+func (stmt AlterTableStmt) DropDefault(name string) AlterTableStmt {
+	return stmt.add(func(d Dialect) (string, error) {
+		if err := canAlterColumn(d, AlterDefault, name); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`ALTER COLUMN "%s" DROP DEFAULT`, name), nil
+	})
+}
+
+func (stmt AlterTableStmt) add(action alterAction) AlterTableStmt {
+	// Copy the actions so statements that share a base do not conflict
+	actions := make([]alterAction, len(stmt.actions), len(stmt.actions)+1)
+	copy(actions, stmt.actions)
+	stmt.actions = append(actions, action)
+	return stmt
+}
+
+// String outputs the parameter-less ALTER TABLE statement in a neutral
+// dialect.
+func (stmt AlterTableStmt) String() string {
+	c, _ := stmt.Compile(&defaultDialect{}, Params())
+	return c
+}
+
+// Compile outputs the ALTER TABLE statement using the given dialect and
+// parameters. An error may be returned because of a pre-existing error,
+// because no actions were given, or because an error occurred during
+// compilation.
+func (stmt AlterTableStmt) Compile(d Dialect, p *Parameters) (string, error) {
+	if err := stmt.Error(); err != nil {
+		return "", err
+	}
+	if len(stmt.actions) == 0 {
+		return "", fmt.Errorf("aspect: ALTER TABLE requires at least one action")
+	}
+	actions := make([]string, len(stmt.actions))
+	var err error
+	for i, action := range stmt.actions {
+		if actions[i], err = action(d); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf(
+		"ALTER TABLE %s %s",
+		stmt.table.Compile(d, p),
+		strings.Join(actions, ", "),
+	), nil
+}
+
+// AlterTable creates an ALTER TABLE statement for the given table.
+func AlterTable(table *TableElem) (stmt AlterTableStmt) {
+	if table == nil {
+		stmt.SetError("aspect: attempting to ALTER a nil table")
+		return
+	}
+	stmt.table = table
+	return
+}
+
+// columnAttributes are appended to SQL types by their Create methods
+var columnAttributes = []string{
+	" PRIMARY KEY", " AUTOINCREMENT", " NOT NULL", " UNIQUE", " DEFAULT ",
+}
+
+// describeType splits the Create output of the given type into its base
+// SQL type, such as VARCHAR(32), whether it is NOT NULL, and its DEFAULT
+// expression, if any.
+func describeType(d Dialect, typ Type) (base string, notNull bool, def string, err error) {
+	compiled, err := typ.Create(d)
+	if err != nil {
+		return
+	}
+	// DEFAULT is always the final attribute
+	if i := strings.Index(compiled, " DEFAULT "); i != -1 {
+		def = compiled[i+len(" DEFAULT "):]
+		compiled = compiled[:i]
+	}
+	notNull = strings.Contains(compiled, " NOT NULL")
+	base = compiled
+	for _, attr := range columnAttributes {
+		if i := strings.Index(base, attr); i != -1 {
+			base = base[:i]
+		}
+	}
+	return
+}
@@ -0,0 +1,38 @@
+package aspect
+
+import "testing"
+
+func TestAlterTableStmt(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+	expect.SQL(
+		`ALTER TABLE "users" ADD COLUMN "password" VARCHAR(128)`,
+		users.Alter().AddColumn(users.C["password"]),
+	)
+	expect.SQL(
+		`ALTER TABLE "users" DROP COLUMN "password", ALTER COLUMN "name" TYPE TEXT`,
+		users.Alter().DropColumn("password").AlterColumnType(
+			"name", Text{NotNull: true},
+		),
+	)
+	expect.SQL(
+		`ALTER TABLE "audit"."logs" ALTER COLUMN "message" SET NOT NULL`,
+		logs.Alter().SetNotNull("message"),
+	)
+	expect.SQL(
+		`ALTER TABLE "users" ALTER COLUMN "password" DROP NOT NULL, ALTER COLUMN "password" SET DEFAULT 'secret', ALTER COLUMN "name" DROP DEFAULT`,
+		users.Alter().DropNotNull("password").SetDefault(
+			"password", "secret",
+		).DropDefault("name"),
+	)
+
+	// Foreign keys include their references
+	expect.SQL(
+		`ALTER TABLE "messages" ADD COLUMN "parent_id" INTEGER REFERENCES messages("id")`,
+		messages.Alter().AddColumn(messages.C["parent_id"]),
+	)
+
+	// At least one action is required
+	expect.Error(users.Alter())
+	expect.Error(AlterTable(nil).DropColumn("id"))
+	expect.Error(users.Alter().SetDefault("name", nil))
+}
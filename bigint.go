@@ -11,9 +11,11 @@ type BigInt struct {
 	NotNull    bool
 	Unique     bool
 	PrimaryKey bool
+	Default    interface{}
 }
 
 var _ Type = BigInt{}
+var _ Defaulter = BigInt{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s BigInt) Create(d Dialect) (string, error) {
@@ -28,18 +30,30 @@ func (s BigInt) Create(d Dialect) (string, error) {
 	if s.Unique {
 		attrs = append(attrs, "UNIQUE")
 	}
+	if HasDefaultValue(s.Default) {
+		def, err := CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		attrs = append(attrs, def)
+	}
 	if len(attrs) > 0 {
 		compiled += fmt.Sprintf(" %s", strings.Join(attrs, " "))
 	}
 	return compiled, nil
 }
 
+// HasDefault implements the Defaulter interface.
+func (s BigInt) HasDefault() bool {
+	return HasDefaultValue(s.Default)
+}
+
 func (s BigInt) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s BigInt) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s BigInt) IsUnique() bool {
@@ -0,0 +1,116 @@
+package aspect
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Args are the values of bound parameters by name, given to Execute or
+// Query in place of positional arguments
+type Args map[string]interface{}
+
+// BindClause is a named parameter whose value is given by name when the
+// statement is executed. It can be used anywhere a value can.
+type BindClause struct {
+	name string
+}
+
+// Bind creates a named parameter. Its value must be given with Args when
+// the statement is executed:
+//
+//  stmt := Users.Select().Where(Users.C["age"].GTE(aspect.Bind("min_age")))
+//  conn.Query(stmt, aspect.Args{"min_age": 21})
+//
+// A name may be bound more than once in the same statement.
+func Bind(name string) BindClause {
+	return BindClause{name: name}
+}
+
+// Name returns the name of the bound parameter
+func (b BindClause) Name() string {
+	return b.name
+}
+
+// String outputs the parameter in a neutral dialect
+func (b BindClause) String() string {
+	compiled, _ := b.Compile(&defaultDialect{}, Params())
+	return compiled
+}
+
+// Compile adds the bound parameter to the parameters, where it will be
+// replaced by its value during execution
+func (b BindClause) Compile(d Dialect, params *Parameters) (string, error) {
+	return d.Parameterize(params.Add(b)), nil
+}
+
+// bindArgs returns the arguments of an execution and converts them with the
+// given converters. Given positional arguments replace the compiled
+// arguments. If Args are given, they replace the bound parameters of the
+// compiled arguments, and every name must be used.
+func bindArgs(converters Converters, compiled, given []interface{}) ([]interface{}, error) {
+	args := given
+	var named Args
+	if len(given) == 1 {
+		named, _ = given[0].(Args)
+	}
+	if len(given) == 0 || named != nil {
+		var err error
+		if args, err = replaceBound(compiled, named); err != nil {
+			return nil, err
+		}
+	}
+	return converters.bind(args)
+}
+
+// replaceBound returns a copy of the compiled arguments with their bound
+// parameters replaced by the named values
+func replaceBound(compiled []interface{}, named Args) ([]interface{}, error) {
+	var args []interface{}
+	used := make(map[string]bool)
+	var missing []string
+	for i, arg := range compiled {
+		bound, ok := arg.(BindClause)
+		if !ok {
+			continue
+		}
+		if args == nil {
+			args = make([]interface{}, len(compiled))
+			copy(args, compiled)
+		}
+		value, ok := named[bound.name]
+		if !ok {
+			if !used[bound.name] {
+				missing = append(missing, bound.name)
+			}
+			used[bound.name] = true
+			continue
+		}
+		used[bound.name] = true
+		args[i] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf(
+			"aspect: no values were given for the bound parameters: %s",
+			strings.Join(missing, ", "),
+		)
+	}
+	var extra []string
+	for name := range named {
+		if !used[name] {
+			extra = append(extra, name)
+		}
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		return nil, fmt.Errorf(
+			"aspect: values were given for unknown bound parameters: %s",
+			strings.Join(extra, ", "),
+		)
+	}
+	if args == nil {
+		return compiled, nil
+	}
+	return args, nil
+}
@@ -0,0 +1,61 @@
+package aspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBind(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+
+	stmt := users.Select().Where(
+		users.C["id"].GTE(Bind("min")),
+		users.C["name"].Equals("admin"),
+		users.C["id"].LTE(Bind("max")),
+	)
+	expect.SQL(
+		`SELECT "users"."id", "users"."name", "users"."password" FROM "users" WHERE ("users"."id" >= $1 AND "users"."name" = $2 AND "users"."id" <= $3)`,
+		stmt,
+		Bind("min"),
+		"admin",
+		Bind("max"),
+	)
+	assert.Equal(t, "$1", Bind("min").String())
+
+	_, params, err := (&DB{dialect: &defaultDialect{}}).compile(stmt)
+	require.Nil(t, err)
+
+	// Named values replace the bound parameters
+	args, err := bindArgs(nil, params.args, []interface{}{Args{"min": 1, "max": 5}})
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{1, "admin", 5}, args)
+	assert.Equal(t, Bind("min"), params.args[0], "Compiled arguments should not be modified")
+
+	// Names must be given and used
+	_, err = bindArgs(nil, params.args, []interface{}{Args{"min": 1}})
+	assert.Equal(t, "aspect: no values were given for the bound parameters: max", err.Error())
+	_, err = bindArgs(nil, params.args, nil)
+	assert.NotNil(t, err)
+	_, err = bindArgs(nil, params.args, []interface{}{Args{"min": 1, "max": 5, "other": 3, "age": 2}})
+	assert.Equal(t, "aspect: values were given for unknown bound parameters: age, other", err.Error())
+	_, err = bindArgs(nil, []interface{}{1}, []interface{}{Args{"min": 1}})
+	assert.NotNil(t, err)
+
+	// Positional arguments still replace all compiled arguments
+	args, err = bindArgs(nil, params.args, []interface{}{2, "client", 3})
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{2, "client", 3}, args)
+
+	// Names may be bound more than once
+	_, params, err = (&DB{dialect: &defaultDialect{}}).compile(
+		users.Update().Values(Values{"name": Bind("name")}).Where(
+			users.C["name"].DoesNotEqual(Bind("name")),
+		),
+	)
+	require.Nil(t, err)
+	args, err = bindArgs(nil, params.args, []interface{}{Args{"name": "admin"}})
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"admin", "admin"}, args)
+}
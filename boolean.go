@@ -1,9 +1,6 @@
 package aspect
 
-import (
-	"fmt"
-	"strings"
-)
+import "fmt"
 
 // Provide a nullable boolean for Boolean type default values
 var (
@@ -14,13 +11,15 @@ var (
 )
 
 // Boolean represents BOOL column types. It contains a Default field that
-// can be left nil, or set with the included variables True and False.
+// can be left nil, or set with a bool, the included variables True and
+// False, or a Clause.
 type Boolean struct {
 	NotNull bool
-	Default *bool
+	Default interface{}
 }
 
 var _ Type = Boolean{}
+var _ Defaulter = Boolean{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s Boolean) Create(d Dialect) (string, error) {
@@ -28,18 +27,27 @@ func (s Boolean) Create(d Dialect) (string, error) {
 	if s.NotNull {
 		compiled += " NOT NULL"
 	}
-	if s.Default != nil {
-		compiled += strings.ToUpper(fmt.Sprintf(" DEFAULT %t", *s.Default))
+	if HasDefaultValue(s.Default) {
+		def, err := CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		compiled += fmt.Sprintf(" %s", def)
 	}
 	return compiled, nil
 }
 
+// HasDefault implements the Defaulter interface.
+func (s Boolean) HasDefault() bool {
+	return HasDefaultValue(s.Default)
+}
+
 func (s Boolean) IsPrimaryKey() bool {
 	return false
 }
 
 func (s Boolean) IsRequired() bool {
-	return s.NotNull && s.Default == nil
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s Boolean) IsUnique() bool {
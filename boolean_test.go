@@ -16,6 +16,7 @@ func TestBoolean(t *testing.T) {
 		"BOOLEAN NOT NULL DEFAULT FALSE",
 		Boolean{NotNull: true, Default: False},
 	)
+	expect.Create("BOOLEAN DEFAULT TRUE", Boolean{Default: true})
 
 	value, err := Boolean{}.Validate(true)
 	assert.Nil(err)
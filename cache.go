@@ -0,0 +1,123 @@
+package aspect
+
+import (
+	"container/list"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// The fields of struct types and their alignments to column lists are
+// cached since they are requested by every scan and insert of a struct.
+// Cached fields are shared and must not be modified.
+var (
+	fieldCache     sync.Map // reflect.Type -> fields
+	alignmentCache = newAlignmentLRU(AlignmentCacheSize)
+)
+
+// AlignmentCacheSize is the number of struct alignments to column lists
+// that are cached. Since column lists may be built dynamically, the least
+// recently used alignment is evicted once the cache is full. An evicted
+// alignment is recomputed by the next scan or insert that needs it.
+const AlignmentCacheSize = 1024
+
+// alignmentKey identifies the alignment of a struct type to an ordered list
+// of columns. The columns are joined by a NUL byte, which cannot appear in
+// column names.
+type alignmentKey struct {
+	elem    reflect.Type
+	columns string
+}
+
+type alignmentEntry struct {
+	key     alignmentKey
+	aligned fields
+}
+
+// alignmentLRU is a least recently used cache of alignments with a fixed
+// capacity. It is safe for concurrent use.
+type alignmentLRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // Most recently used first
+	entries  map[alignmentKey]*list.Element
+}
+
+func newAlignmentLRU(capacity int) *alignmentLRU {
+	return &alignmentLRU{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[alignmentKey]*list.Element),
+	}
+}
+
+// load returns the cached alignment of the given key, if it exists, and
+// marks it as the most recently used
+func (c *alignmentLRU) load(key alignmentKey) (fields, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*alignmentEntry).aligned, true
+}
+
+// store caches the alignment of the given key, evicting the least recently
+// used alignments if the cache is full
+func (c *alignmentLRU) store(key alignmentKey, aligned fields) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*alignmentEntry).aligned = aligned
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&alignmentEntry{key: key, aligned: aligned})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*alignmentEntry).key)
+	}
+}
+
+// clear removes every cached alignment
+func (c *alignmentLRU) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[alignmentKey]*list.Element)
+}
+
+// len returns the number of cached alignments
+func (c *alignmentLRU) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// cachedFields returns the fields of the given struct type, using the cache
+// if possible.
+func cachedFields(elem reflect.Type) fields {
+	if cached, ok := fieldCache.Load(elem); ok {
+		return cached.(fields)
+	}
+	f := SelectFieldsFromElem(elem)
+	fieldCache.Store(elem, f)
+	return f
+}
+
+// cachedAlignment returns the fields of the given struct type aligned to
+// the given columns, using the cache if possible. The unaligned fields are
+// also returned.
+func cachedAlignment(elem reflect.Type, columns []string) (aligned, unaligned fields) {
+	unaligned = cachedFields(elem)
+	key := alignmentKey{elem: elem, columns: strings.Join(columns, "\x00")}
+	if cached, ok := alignmentCache.load(key); ok {
+		return cached, unaligned
+	}
+	aligned = AlignColumns(columns, unaligned)
+	alignmentCache.store(key, aligned)
+	return aligned, unaligned
+}
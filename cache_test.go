@@ -0,0 +1,119 @@
+package aspect
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cachedUser struct {
+	Name     string `db:"name"`
+	Password string `db:"password"`
+	embeddedID
+}
+
+var cachedColumns = []string{"id", "name", "password"}
+
+func TestCachedAlignment(t *testing.T) {
+	elem := reflect.TypeOf(cachedUser{})
+	expected := AlignColumns(cachedColumns, SelectFieldsFromElem(elem))
+
+	// Concurrent requests must return the same alignment
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			aligned, unaligned := cachedAlignment(elem, cachedColumns)
+			assert.Equal(t, expected, aligned)
+			assert.Equal(t, SelectFieldsFromElem(elem), unaligned)
+		}()
+	}
+	wg.Wait()
+
+	// Alignments are cached per column list
+	aligned, _ := cachedAlignment(elem, []string{"password", "id"})
+	assert.Equal(t, 2, len(aligned))
+	assert.Equal(t, "password", aligned[0].column)
+	assert.Equal(t, "id", aligned[1].column)
+}
+
+func TestAlignmentLRU(t *testing.T) {
+	elem := reflect.TypeOf(cachedUser{})
+	key := func(columns ...string) alignmentKey {
+		return alignmentKey{elem: elem, columns: strings.Join(columns, "\x00")}
+	}
+	cache := newAlignmentLRU(2)
+	cache.store(key("id"), fields{{column: "id"}})
+	cache.store(key("name"), fields{{column: "name"}})
+
+	// Loading marks an alignment as recently used
+	_, ok := cache.load(key("id"))
+	assert.True(t, ok)
+
+	// The least recently used alignment is evicted once full
+	cache.store(key("password"), fields{{column: "password"}})
+	assert.Equal(t, 2, cache.len())
+	_, ok = cache.load(key("name"))
+	assert.False(t, ok)
+	aligned, ok := cache.load(key("id"))
+	assert.True(t, ok)
+	assert.Equal(t, "id", aligned[0].column)
+
+	cache.clear()
+	assert.Equal(t, 0, cache.len())
+
+	// Dynamic column lists cannot grow the shared cache without limit
+	for i := 0; i < AlignmentCacheSize+10; i++ {
+		cachedAlignment(elem, []string{"id", strconv.Itoa(i)})
+	}
+	assert.Equal(t, AlignmentCacheSize, alignmentCache.len())
+}
+
+func BenchmarkAlignment_Uncached(b *testing.B) {
+	elem := reflect.TypeOf(cachedUser{})
+	for i := 0; i < b.N; i++ {
+		AlignColumns(cachedColumns, SelectFieldsFromElem(elem))
+	}
+}
+
+func BenchmarkAlignment_Cached(b *testing.B) {
+	elem := reflect.TypeOf(cachedUser{})
+	for i := 0; i < b.N; i++ {
+		cachedAlignment(elem, cachedColumns)
+	}
+}
+
+func BenchmarkAlignment_CachedParallel(b *testing.B) {
+	elem := reflect.TypeOf(cachedUser{})
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cachedAlignment(elem, cachedColumns)
+		}
+	})
+}
+
+func BenchmarkResult_All(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var users []cachedUser
+		newMockResultN(10, cachedColumns...).All(&users)
+	}
+}
+
+func BenchmarkResult_One(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var user cachedUser
+		newMockResultN(1, cachedColumns...).One(&user)
+	}
+}
+
+func BenchmarkInsert_Values(b *testing.B) {
+	user := cachedUser{Name: "admin", Password: "secret"}
+	for i := 0; i < b.N; i++ {
+		users.Insert().Values(user)
+	}
+}
@@ -28,7 +28,7 @@ func (c ColumnClause) Compile(d Dialect, params *Parameters) (string, error) {
 	if c.table == nil {
 		return fmt.Sprintf(`"%s"`, c.name), nil
 	} else {
-		return fmt.Sprintf(`"%s"."%s"`, c.table.name, c.name), nil
+		return fmt.Sprintf(`%s."%s"`, c.table.Compile(d, params), c.name), nil
 	}
 }
 
@@ -0,0 +1,311 @@
+// Command aspect performs schema and migration tasks for databases
+// configured with either an aspect JSON config or a goose dbconf.yml.
+//
+// Usage:
+//
+//  aspect [flags] migrate up|down|redo|status|to VERSION
+//  aspect [flags] schema dump
+//  aspect [flags] schema diff [-target ENV] [-write NAME]
+//  aspect [flags] gen [-package NAME] [-ddl FILE] [-structs=false] [TABLE...]
+//
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aodin/aspect"
+	"github.com/aodin/aspect/config"
+	"github.com/aodin/aspect/gen"
+	"github.com/aodin/aspect/migrate"
+
+	// Register the included dialects
+	_ "github.com/aodin/aspect/mysql"
+	_ "github.com/aodin/aspect/postgres"
+	_ "github.com/aodin/aspect/sqlite3"
+)
+
+const usage = `Usage: aspect [flags] <command> [arguments]
+
+Commands:
+  migrate up            Apply all pending migrations
+  migrate down          Roll back the latest migration
+  migrate redo          Roll back and re-apply the latest migration
+  migrate status        List migrations and when they were applied
+  migrate to VERSION    Migrate up or down to the given version
+  schema dump           Output the CREATE statements of every table
+  schema diff           Output a goose migration to the -target schema
+  gen                   Output Go declarations of tables and their rows
+
+Flags:
+`
+
+// options are the global flags
+type options struct {
+	config string
+	goose  string
+	env    string
+	dir    string
+}
+
+func main() {
+	var opts options
+	flags := flag.NewFlagSet("aspect", flag.ExitOnError)
+	flags.StringVar(&opts.config, "config", "", "path to an aspect JSON database config")
+	flags.StringVar(&opts.goose, "goose", "db/dbconf.yml", "path to a goose dbconf.yml")
+	flags.StringVar(&opts.env, "env", "development", "goose database environment")
+	flags.StringVar(&opts.dir, "dir", "db/migrations", "directory of goose SQL migrations")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+
+	if err := run(opts, flags.Args(), os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "aspect: %s\n", err)
+		if err == errUsage {
+			flags.Usage()
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+var errUsage = fmt.Errorf("invalid command")
+
+func run(opts options, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "migrate":
+		return runMigrate(opts, args[1:], w)
+	case "schema":
+		return runSchema(opts, args[1:], w)
+	case "gen":
+		return runGen(opts, args[1:], w)
+	}
+	return errUsage
+}
+
+// connect connects to the database given by either the aspect config or
+// the goose environment
+func connect(opts options, env string) (*aspect.DB, error) {
+	var db config.Database
+	var err error
+	if opts.config != "" {
+		db, err = config.ParseConfig(opts.config)
+	} else {
+		db, err = config.ParseGooseDatabase(opts.goose, env)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return aspect.Connect(db.Driver, db.Credentials())
+}
+
+// warnUnresolved writes any foreign keys that were reflected as plain
+// columns to standard error, since the reflected tables are still usable
+func warnUnresolved(err error) error {
+	var unresolved aspect.UnresolvedForeignKeys
+	if errors.As(err, &unresolved) {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return nil
+	}
+	return err
+}
+
+// reflectAll reflects every table of the database except the versions
+// table of the migrate package
+func reflectAll(conn aspect.Connection) (*aspect.MetaData, error) {
+	reflected, err := aspect.ReflectAll(conn)
+	if err = warnUnresolved(err); err != nil {
+		return nil, err
+	}
+	meta := aspect.NewMetaData()
+	for _, table := range reflected.Tables() {
+		if table.Name() == migrate.Versions.Name() {
+			continue
+		}
+		if err := meta.Add(table); err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+func runMigrate(opts options, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	conn, err := connect(opts, opts.env)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	m, err := migrate.New(conn, opts.dir)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return m.Up()
+	case "down":
+		return m.Down()
+	case "redo":
+		return m.Redo()
+	case "to":
+		if len(args) != 2 {
+			return errUsage
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		return m.To(version)
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "Version\tName\tApplied At")
+		for _, status := range statuses {
+			applied := "Pending"
+			if status.Applied {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return tw.Flush()
+	}
+	return errUsage
+}
+
+func runSchema(opts options, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	conn, err := connect(opts, opts.env)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	current, err := reflectAll(conn)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "dump":
+		tables, err := current.Sorted()
+		if err != nil {
+			return err
+		}
+		for _, table := range tables {
+			compiled, err := table.Create().Compile(conn.Dialect(), aspect.Params())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s\n\n", compiled)
+		}
+		return nil
+
+	case "diff":
+		flags := flag.NewFlagSet("schema diff", flag.ExitOnError)
+		target := flags.String("target", "", "goose environment of the target schema")
+		write := flags.String("write", "", "write the migration to -dir with the given name")
+		flags.Parse(args[1:])
+		if *target == "" {
+			return fmt.Errorf("schema diff requires a -target environment")
+		}
+
+		targetConn, err := connect(options{goose: opts.goose}, *target)
+		if err != nil {
+			return err
+		}
+		defer targetConn.Close()
+		desired, err := reflectAll(targetConn)
+		if err != nil {
+			return err
+		}
+
+		changes, err := aspect.Diff(current, desired, conn.Dialect())
+		if err != nil {
+			return err
+		}
+		if *write == "" {
+			return changes.WriteGoose(w, conn.Dialect())
+		}
+		path := filepath.Join(opts.dir, aspect.GooseFilename(time.Now(), *write))
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := changes.WriteGoose(f, conn.Dialect()); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Created %s\n", path)
+		return nil
+	}
+	return errUsage
+}
+
+func runGen(opts options, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	pkg := flags.String("package", "db", "package name of the generated source")
+	ddl := flags.String("ddl", "", "parse the CREATE TABLE statements of a SQL file instead of connecting")
+	structs := flags.Bool("structs", true, "also output a struct for the rows of each table")
+	flags.Parse(args)
+
+	var meta *aspect.MetaData
+	if *ddl != "" {
+		f, err := os.Open(*ddl)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		meta, err = gen.ParseDDL(f)
+		if err = warnUnresolved(err); err != nil {
+			return err
+		}
+	} else {
+		conn, err := connect(opts, opts.env)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if meta, err = reflectAll(conn); err != nil {
+			return err
+		}
+	}
+	tables, err := meta.Sorted()
+	if err != nil {
+		return err
+	}
+
+	// Only output the requested tables, if any were given
+	if names := flags.Args(); len(names) > 0 {
+		tables = make([]*aspect.TableElem, len(names))
+		for i, name := range names {
+			if tables[i] = meta.Table(name); tables[i] == nil {
+				return fmt.Errorf("no table named %s", name)
+			}
+		}
+	}
+	if *structs {
+		return gen.Generate(w, *pkg, tables...)
+	}
+	return gen.Tables(w, *pkg, tables...)
+}
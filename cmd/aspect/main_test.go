@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aspect")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	goose := filepath.Join(dir, "dbconf.yml")
+	require.Nil(t, ioutil.WriteFile(goose, []byte(`development:
+    driver: sqlite3
+    open: `+filepath.Join(dir, "development.db")+`
+
+target:
+    driver: sqlite3
+    open: `+filepath.Join(dir, "target.db")+`
+`), 0644))
+
+	migrations := filepath.Join(dir, "migrations")
+	require.Nil(t, os.Mkdir(migrations, 0755))
+	require.Nil(t, ioutil.WriteFile(
+		filepath.Join(migrations, "20150101000000_create_teams.sql"),
+		[]byte(`-- +goose Up
+CREATE TABLE "teams" ("id" INTEGER NOT NULL, PRIMARY KEY ("id"));
+
+-- +goose Down
+DROP TABLE "teams";
+`),
+		0644,
+	))
+
+	opts := options{goose: goose, env: "development", dir: migrations}
+	var out bytes.Buffer
+
+	assert.Equal(t, errUsage, run(opts, nil, &out))
+	assert.Equal(t, errUsage, run(opts, []string{"unknown"}, &out))
+
+	require.Nil(t, run(opts, []string{"migrate", "up"}, &out))
+	require.Nil(t, run(opts, []string{"migrate", "status"}, &out))
+	assert.Contains(t, out.String(), "20150101000000  create_teams")
+
+	out.Reset()
+	require.Nil(t, run(opts, []string{"schema", "dump"}, &out))
+	assert.Contains(t, out.String(), `CREATE TABLE "teams" (`)
+	assert.NotContains(t, out.String(), "goose_db_version")
+
+	out.Reset()
+	require.Nil(t, run(opts, []string{"gen", "-package", "models"}, &out))
+	assert.Contains(t, out.String(), "package models")
+	assert.Contains(t, out.String(), `var Teams = aspect.Table("teams",`)
+	assert.Contains(t, out.String(), "type Team struct {")
+
+	// The empty target database requires the teams table to be dropped
+	out.Reset()
+	require.Nil(t, run(
+		opts, []string{"schema", "diff", "-target", "target"}, &out,
+	))
+	assert.True(t, strings.HasPrefix(
+		out.String(), "-- +goose Up\nDROP TABLE \"teams\";\n\n-- +goose Down\n",
+	), "unexpected diff: %s", out.String())
+
+	require.Nil(t, run(opts, []string{"migrate", "down"}, &out))
+	assert.NotNil(t, run(opts, []string{"migrate", "to", "x"}, &out))
+}
@@ -84,7 +84,7 @@ func (c ColumnElem) Compile(d Dialect, params *Parameters) (string, error) {
 	var err error
 	if c.inner == nil {
 		// Old behavior
-		compiled, err = fmt.Sprintf(`"%s"."%s"`, c.table.name, c.name), nil
+		compiled, err = c.clause().Compile(d, params)
 	} else {
 		compiled, err = c.inner.Compile(d, params)
 	}
@@ -112,6 +112,13 @@ func (c ColumnElem) Type() Type {
 	return c.typ
 }
 
+// HasDefault returns true if the column's type has a DEFAULT. Only types
+// that implement the Defaulter interface can have defaults.
+func (c ColumnElem) HasDefault() bool {
+	defaulter, ok := c.typ.(Defaulter)
+	return ok && defaulter.HasDefault()
+}
+
 // Selectable implements the sql.Selectable interface for building SELECT
 // statements from ColumnElem instances.
 func (c ColumnElem) Selectable() []ColumnElem {
@@ -18,11 +18,23 @@ type Database struct {
 	User     string `json:"user"`
 	Password string `json:"password"`
 	SSLMode  string `json:"ssl_mode"`
+
+	// SearchPath is a comma separated list of postgres schemas that will
+	// be set as the search_path of every connection
+	SearchPath string `json:"search_path"`
+
+	// DSN is a driver specific data source name, such as a sqlite3 file
+	// path. If set, it will be used as the credentials as is.
+	DSN string `json:"dsn"`
 }
 
 // Credentials with return a string of credentials appropriate for Go's
 // sql.Open function
 func (db Database) Credentials() string {
+	if db.DSN != "" {
+		return db.DSN
+	}
+
 	// Only add the key if there is a value
 	var values []string
 	if db.Host != "" {
@@ -43,6 +55,9 @@ func (db Database) Credentials() string {
 	if db.SSLMode != "" {
 		values = append(values, fmt.Sprintf("sslmode=%s", db.SSLMode))
 	}
+	if db.SearchPath != "" {
+		values = append(values, fmt.Sprintf("search_path=%s", db.SearchPath))
+	}
 	return strings.Join(values, " ")
 }
 
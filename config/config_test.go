@@ -14,4 +14,11 @@ func TestDatabase(t *testing.T) {
 		`host=localhost port=5432 dbname=aspect_test user=postgres sslmode=disable`,
 		c.Credentials(),
 	)
+
+	// A search path should be added to the credentials
+	c.SearchPath = "audit,public"
+	assert.Equal(t,
+		`host=localhost port=5432 dbname=aspect_test user=postgres sslmode=disable search_path=audit,public`,
+		c.Credentials(),
+	)
 }
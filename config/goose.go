@@ -56,6 +56,14 @@ func ParseGooseYAML(path string) (conf map[string]Database, err error) {
 	for name, db := range goose {
 		c := Database{Driver: db.Driver}
 
+		// Only postgres uses key=value open strings, other drivers, such
+		// as sqlite3 and mysql, use their own data source names
+		if db.Driver != "postgres" {
+			c.DSN = db.Open
+			conf[name] = c
+			continue
+		}
+
 		// Split the open string
 		// TODO common operation for doing this
 		attrs := strings.Split(db.Open, " ")
@@ -74,13 +82,16 @@ func ParseGooseYAML(path string) (conf map[string]Database, err error) {
 
 		// Yup
 		c.Host = m["host"]
-		if c.Port, err = strconv.ParseInt(m["port"], 10, 64); err != nil {
-			return
+		if m["port"] != "" {
+			if c.Port, err = strconv.ParseInt(m["port"], 10, 64); err != nil {
+				return
+			}
 		}
 		c.Name = m["dbname"]
 		c.User = m["user"]
 		c.Password = m["password"]
 		c.SSLMode = m["sslmode"]
+		c.SearchPath = m["search_path"]
 		conf[name] = c
 	}
 	return
@@ -15,3 +15,10 @@ func TestParseTestYAML(t *testing.T) {
 		c.Credentials(),
 	)
 }
+
+func TestParseTestYAML_DSN(t *testing.T) {
+	c, err := ParseGooseDatabase("./example.dbconf.yml", "local")
+	assert.Nil(t, err)
+	assert.Equal(t, "sqlite3", c.Driver)
+	assert.Equal(t, "local.db", c.Credentials())
+}
@@ -0,0 +1,311 @@
+package aspect
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// Converter converts values of a Go type to and from driver values. It
+// allows types that do not implement sql.Scanner and driver.Valuer to be
+// used as parameters and scan destinations.
+type Converter interface {
+	// Value converts a value of the registered type to a driver value
+	Value(v interface{}) (driver.Value, error)
+
+	// Scan sets the value pointed to by dest, which is a pointer to the
+	// registered type, from the given driver value
+	Scan(dest, src interface{}) error
+}
+
+// Converters maps Go types to their converters
+type Converters map[reflect.Type]Converter
+
+var (
+	globalConverters = Converters{}
+	convertersMutex  sync.RWMutex
+
+	// convertedTypes are the types with a registered converter. Their
+	// fields will not be selected, even if they are structs.
+	convertedTypes sync.Map // reflect.Type -> bool
+)
+
+// RegisterConverter registers the converter for the type of the given
+// value with every connection. Converters should be registered before any
+// statements using the type are built. Struct types that would otherwise
+// have their fields selected can only be converted by registering them:
+//
+//  func init() {
+//      aspect.RegisterConverter(Settings{}, aspect.JSONConverter{})
+//      aspect.RegisterConverter(net.IP{}, aspect.TextConverter{})
+//  }
+//
+func RegisterConverter(v interface{}, c Converter) {
+	t := reflect.TypeOf(v)
+	convertersMutex.Lock()
+	globalConverters[t] = c
+	convertersMutex.Unlock()
+	registerConvertedType(t)
+}
+
+// registerConvertedType marks the given type as converted and clears any
+// cached fields that may have selected its fields
+func registerConvertedType(t reflect.Type) {
+	if _, exists := convertedTypes.LoadOrStore(t, true); exists {
+		return
+	}
+	fieldCache.Range(func(key, _ interface{}) bool {
+		fieldCache.Delete(key)
+		return true
+	})
+	alignmentCache.clear()
+}
+
+// isConverted returns true if the type has a registered converter
+func isConverted(t reflect.Type) bool {
+	_, ok := convertedTypes.Load(t)
+	return ok
+}
+
+// with returns a copy of the converters with the given converter added.
+// Since statements select the fields of structs before the connection that
+// executes them is known, it panics if given a struct type whose fields
+// would be selected.
+func (c Converters) with(v interface{}, converter Converter) Converters {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		log.Panic("aspect: converters cannot be added for nil")
+	}
+	if isNested(t) || (t.Kind() == reflect.Ptr && isNested(t.Elem())) {
+		log.Panicf(
+			"aspect: the struct type %s must be converted with RegisterConverter",
+			t,
+		)
+	}
+	copied := make(Converters, len(c)+1)
+	for key, value := range c {
+		copied[key] = value
+	}
+	copied[t] = converter
+	return copied
+}
+
+// destKind returns the kind of the given destination type, with the types
+// of the converters scanned as native types
+func (c Converters) destKind(t reflect.Type) reflect.Kind {
+	if _, ok := c[t]; ok {
+		return reflect.Invalid
+	}
+	return destKind(t)
+}
+
+// lookup returns the converter of the given type, preferring the
+// converters of the connection over those registered globally
+func (c Converters) lookup(t reflect.Type) Converter {
+	if converter, ok := c[t]; ok {
+		return converter
+	}
+	convertersMutex.RLock()
+	defer convertersMutex.RUnlock()
+	return globalConverters[t]
+}
+
+// empty returns true if there are no converters to apply
+func (c Converters) empty() bool {
+	if len(c) > 0 {
+		return false
+	}
+	convertersMutex.RLock()
+	defer convertersMutex.RUnlock()
+	return len(globalConverters) == 0
+}
+
+// bind converts any arguments of converted types, or pointers to them, into
+// driver values. The given arguments are not modified.
+func (c Converters) bind(args []interface{}) ([]interface{}, error) {
+	if c.empty() {
+		return args, nil
+	}
+	var bound []interface{}
+	for i, arg := range args {
+		value := reflect.ValueOf(arg)
+		if !value.IsValid() {
+			continue
+		}
+		converter := c.lookup(value.Type())
+		if converter == nil && value.Kind() == reflect.Ptr {
+			if converter = c.lookup(value.Type().Elem()); converter != nil {
+				if value.IsNil() {
+					arg = nil
+				} else {
+					arg = value.Elem().Interface()
+				}
+			}
+		}
+		if converter == nil {
+			continue
+		}
+		if bound == nil {
+			bound = make([]interface{}, len(args))
+			copy(bound, args)
+		}
+		if arg == nil {
+			bound[i] = nil
+			continue
+		}
+		converted, err := converter.Value(arg)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"aspect: failed to convert parameter %d of type %T: %s",
+				i+1, arg, err,
+			)
+		}
+		bound[i] = converted
+	}
+	if bound == nil {
+		return args, nil
+	}
+	return bound, nil
+}
+
+// scanner returns a sql.Scanner for the given destination if it is a
+// pointer to a converted type, or to a pointer to one, otherwise nil
+func (c Converters) scanner(dest interface{}) *convertScanner {
+	t := reflect.TypeOf(dest)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil
+	}
+	if converter := c.lookup(t.Elem()); converter != nil {
+		return &convertScanner{converter: converter, dest: reflect.ValueOf(dest).Elem()}
+	}
+	if t.Elem().Kind() != reflect.Ptr {
+		return nil
+	}
+	if converter := c.lookup(t.Elem().Elem()); converter != nil {
+		return &convertScanner{converter: converter, dest: reflect.ValueOf(dest).Elem(), ptr: true}
+	}
+	return nil
+}
+
+// convertScanner scans driver values into a destination using a converter
+type convertScanner struct {
+	converter Converter
+	dest      reflect.Value
+	ptr       bool // dest is a pointer that will be nil for NULL values
+}
+
+// Scan implements the sql.Scanner interface
+func (s *convertScanner) Scan(src interface{}) error {
+	if !s.ptr {
+		return s.converter.Scan(s.dest.Addr().Interface(), src)
+	}
+	if src == nil {
+		s.dest.Set(reflect.Zero(s.dest.Type()))
+		return nil
+	}
+	value := reflect.New(s.dest.Type().Elem())
+	if err := s.converter.Scan(value.Interface(), src); err != nil {
+		return err
+	}
+	s.dest.Set(value)
+	return nil
+}
+
+// convertedRows wraps result rows, converting the destinations of scans
+type convertedRows struct {
+	Scanner
+	converters Converters
+}
+
+// Scan converts any destinations of converted types before scanning
+func (rows convertedRows) Scan(dest ...interface{}) error {
+	converted := make([]interface{}, len(dest))
+	for i, d := range dest {
+		if s := rows.converters.scanner(d); s != nil {
+			converted[i] = s
+		} else {
+			converted[i] = d
+		}
+	}
+	return rows.Scanner.Scan(converted...)
+}
+
+// JSONConverter stores values as JSON, such as structs in postgres.JSON
+// columns. NULL values are scanned as the zero value.
+type JSONConverter struct{}
+
+// Value encodes the given value as JSON text
+func (JSONConverter) Value(v interface{}) (driver.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan decodes the JSON source into the destination
+func (JSONConverter) Scan(dest, src interface{}) error {
+	b, err := bytesOf(src)
+	if err != nil || b == nil {
+		zero(dest)
+		return err
+	}
+	return json.Unmarshal(b, dest)
+}
+
+// TextConverter stores values as text using their encoding.TextMarshaler
+// and encoding.TextUnmarshaler methods, such as net.IP in postgres.Inet
+// columns, most UUID types, and enums. NULL values are scanned as the zero
+// value.
+type TextConverter struct{}
+
+// Value encodes the given value as text
+func (TextConverter) Value(v interface{}) (driver.Value, error) {
+	marshaler, ok := v.(encoding.TextMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement encoding.TextMarshaler", v)
+	}
+	b, err := marshaler.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan decodes the text source into the destination
+func (TextConverter) Scan(dest, src interface{}) error {
+	unmarshaler, ok := dest.(encoding.TextUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%T does not implement encoding.TextUnmarshaler", dest)
+	}
+	b, err := bytesOf(src)
+	if err != nil || b == nil {
+		zero(dest)
+		return err
+	}
+	return unmarshaler.UnmarshalText(b)
+}
+
+// bytesOf returns the bytes of a string or []byte driver value, or nil if
+// the value is NULL
+func bytesOf(src interface{}) ([]byte, error) {
+	switch s := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return s, nil
+	case string:
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to text", src)
+}
+
+// zero sets the value pointed to by the given pointer to its zero value
+func zero(ptr interface{}) {
+	v := reflect.ValueOf(ptr).Elem()
+	v.Set(reflect.Zero(v.Type()))
+}
@@ -0,0 +1,106 @@
+package aspect
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type convertedSettings struct {
+	Theme string `json:"theme"`
+}
+
+type userWithSettings struct {
+	ID       int64             `db:"id"`
+	Settings convertedSettings `db:"settings"`
+}
+
+type unconvertedSettings struct {
+	Theme string `db:"theme"`
+}
+
+type userWithUnconverted struct {
+	ID       int64               `db:"id"`
+	Settings unconvertedSettings `db:"settings"`
+}
+
+func init() {
+	RegisterConverter(convertedSettings{}, JSONConverter{})
+}
+
+func TestConverters(t *testing.T) {
+	converters := Converters{}.with(net.IP{}, TextConverter{})
+
+	// Registered structs are not nested
+	assert.False(t, isNested(reflect.TypeOf(convertedSettings{})))
+	fields := SelectFieldsFromElem(reflect.TypeOf(userWithSettings{}))
+	require.Equal(t, 2, len(fields))
+	assert.Equal(t, "settings", fields[1].column)
+
+	// Parameters of converted types and pointers to them are converted
+	settings := convertedSettings{Theme: "dark"}
+	var nilSettings *convertedSettings
+	args := []interface{}{1, settings, &settings, nilSettings, net.ParseIP("127.0.0.1")}
+	bound, err := converters.bind(args)
+	require.Nil(t, err)
+	assert.Equal(t,
+		[]interface{}{1, `{"theme":"dark"}`, `{"theme":"dark"}`, nil, "127.0.0.1"},
+		bound,
+	)
+	assert.Equal(t, settings, args[1], "Given arguments should not be modified")
+
+	// Destinations of converted types are scanned with the converter
+	var scanned convertedSettings
+	s := converters.scanner(&scanned)
+	require.NotNil(t, s)
+	assert.Nil(t, s.Scan([]byte(`{"theme":"light"}`)))
+	assert.Equal(t, "light", scanned.Theme)
+	assert.Nil(t, s.Scan(nil))
+	assert.Equal(t, convertedSettings{}, scanned)
+
+	var ptr *convertedSettings
+	s = converters.scanner(&ptr)
+	require.NotNil(t, s)
+	assert.Nil(t, s.Scan(`{"theme":"light"}`))
+	require.NotNil(t, ptr)
+	assert.Equal(t, "light", ptr.Theme)
+	assert.Nil(t, s.Scan(nil))
+	assert.Nil(t, ptr)
+
+	var ip net.IP
+	s = converters.scanner(&ip)
+	require.NotNil(t, s)
+	assert.Nil(t, s.Scan("10.0.0.1"))
+	assert.Equal(t, "10.0.0.1", ip.String())
+	assert.NotNil(t, s.Scan(int64(1)))
+
+	var id int64
+	assert.Nil(t, converters.scanner(&id))
+
+	// Errors during conversion are returned
+	_, err = Converters{}.with(0.0, TextConverter{}).bind([]interface{}{1.5})
+	assert.NotNil(t, err)
+}
+
+func TestConverters_Connection(t *testing.T) {
+	// The fields of structs do not depend on the converters of connections
+	assert.Panics(t, func() {
+		Converters{}.with(unconvertedSettings{}, JSONConverter{})
+	})
+	assert.Panics(t, func() {
+		Converters{}.with(&unconvertedSettings{}, JSONConverter{})
+	})
+	fields := SelectFieldsFromElem(reflect.TypeOf(userWithUnconverted{}))
+	require.Equal(t, 2, len(fields))
+	assert.Equal(t, "theme", fields[1].column)
+
+	// Other types are scanned whole only by connections that convert them
+	tags := reflect.TypeOf(map[string]string{})
+	converters := Converters{}.with(map[string]string{}, JSONConverter{})
+	assert.Equal(t, reflect.Invalid, converters.destKind(tags))
+	assert.Equal(t, reflect.Map, Converters{}.destKind(tags))
+	assert.False(t, isConverted(tags))
+}
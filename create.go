@@ -13,7 +13,35 @@ type Creatable interface {
 
 // CreateStmt is the internal representation of an CREATE TABLE statement.
 type CreateStmt struct {
-	table *TableElem
+	table       *TableElem
+	ifNotExists bool
+	temporary   bool
+	as          Compiles
+}
+
+// As creates the table from the results of the given SELECT statement,
+// rather than from the table's columns and constraints.
+func (stmt CreateStmt) As(selection SelectStmt) CreateStmt {
+	stmt.as = selection
+	return stmt
+}
+
+// IfNotExists adds the IF NOT EXISTS modifier to a CREATE TABLE statement.
+func (stmt CreateStmt) IfNotExists() CreateStmt {
+	stmt.ifNotExists = true
+	return stmt
+}
+
+// Temporary creates the table as a TEMPORARY table, which will be
+// automatically dropped at the end of the session.
+func (stmt CreateStmt) Temporary() CreateStmt {
+	stmt.temporary = true
+	return stmt
+}
+
+// Table returns the table of this statement
+func (stmt CreateStmt) Table() *TableElem {
+	return stmt.table
 }
 
 // String outputs the parameter-less CREATE TABLE statement in a neutral
@@ -27,19 +55,38 @@ func (stmt CreateStmt) String() string {
 // parameters. An error may be returned because of a pre-existing error or
 // because an error occurred during compilation.
 func (stmt CreateStmt) Compile(d Dialect, p *Parameters) (string, error) {
+	compiled := "CREATE"
+	if stmt.temporary {
+		compiled += " TEMPORARY"
+	}
+	compiled += " TABLE"
+	if stmt.ifNotExists {
+		compiled += " IF NOT EXISTS"
+	}
+	compiled += fmt.Sprintf(" %s", stmt.table.Compile(d, p))
+
+	// CREATE TABLE ... AS SELECT ...
+	if stmt.as != nil {
+		as, err := stmt.as.Compile(d, p)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s AS %s", compiled, as), nil
+	}
+
 	// Compiled elements
-	compiled := make([]string, len(stmt.table.creates))
+	creates := make([]string, len(stmt.table.creates))
 
 	var err error
 	for i, create := range stmt.table.creates {
-		if compiled[i], err = create.Create(d); err != nil {
+		if creates[i], err = create.Create(d); err != nil {
 			return "", err
 		}
 	}
 
 	return fmt.Sprintf(
-		"CREATE TABLE \"%s\" (\n  %s\n);",
-		stmt.table.Name(),
-		strings.Join(compiled, ",\n  "),
+		"%s (\n  %s\n);",
+		compiled,
+		strings.Join(creates, ",\n  "),
 	), nil
 }
@@ -20,4 +20,31 @@ func TestCreateStmt(t *testing.T) {
   UNIQUE ("a", "b")
 );`
 	expect.SQL(expected, attrs.Create())
+
+	expected = `CREATE TABLE IF NOT EXISTS "attrs" (
+  "id" INTEGER PRIMARY KEY,
+  "a" INTEGER,
+  "b" INTEGER,
+  UNIQUE ("a", "b")
+);`
+	expect.SQL(expected, attrs.Create().IfNotExists())
+
+	expected = `CREATE TEMPORARY TABLE "attrs" (
+  "id" INTEGER PRIMARY KEY,
+  "a" INTEGER,
+  "b" INTEGER,
+  UNIQUE ("a", "b")
+);`
+	expect.SQL(expected, attrs.Create().Temporary())
+
+	// Tables can be created from a SELECT statement
+	expect.SQL(
+		`CREATE TABLE IF NOT EXISTS "edges" AS SELECT "attrs"."a", "attrs"."b" FROM "attrs" WHERE "attrs"."id" > $1`,
+		edges.Create().IfNotExists().As(
+			Select(attrs.C["a"], attrs.C["b"]).Where(
+				attrs.C["id"].GreaterThan(1),
+			),
+		),
+		1,
+	)
 }
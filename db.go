@@ -2,6 +2,7 @@ package aspect
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 )
 
@@ -26,6 +27,28 @@ type Connection interface {
 var _ Connection = &DB{}
 var _ Connection = &TX{}
 
+// DialectConnection is an optional interface for connections that report
+// their dialect, such as DB and TX. It is not part of Connection so that
+// existing implementations of Connection remain valid.
+type DialectConnection interface {
+	Connection
+	Dialect() Dialect
+}
+
+var _ DialectConnection = &DB{}
+var _ DialectConnection = &TX{}
+
+// ConnectionDialect returns the dialect of the given connection, or an
+// error if the connection does not report its dialect
+func ConnectionDialect(conn Connection) (Dialect, error) {
+	if dc, ok := conn.(DialectConnection); ok {
+		return dc.Dialect(), nil
+	}
+	return nil, fmt.Errorf(
+		"aspect: connection %T does not report its dialect", conn,
+	)
+}
+
 type Transaction interface {
 	Connection
 	Commit() error
@@ -50,21 +73,102 @@ type Executable interface {
 // DB wraps the current sql.DB connection pool and includes the Dialect
 // associated with the connection.
 type DB struct {
-	conn    *sql.DB
-	dialect Dialect
+	conn       *sql.DB
+	dialect    Dialect
+	strict     bool
+	converters Converters
+	middleware middleware
+	cache      *stmtCache
 }
 
 // Begin starts a new transaction using the current database connection pool.
 func (db *DB) Begin() (Transaction, error) {
 	tx, err := db.conn.Begin()
-	return &TX{Tx: tx, dialect: db.dialect}, err
+	return db.wrapTx(tx), err
+}
+
+// wrapTx wraps the given transaction with the dialect and options of the
+// database connection pool
+func (db *DB) wrapTx(tx *sql.Tx) *TX {
+	return &TX{
+		Tx:         tx,
+		dialect:    db.dialect,
+		strict:     db.strict,
+		converters: db.converters,
+		middleware: db.middleware,
+		cache:      db.cache,
+		savepoints: new(int),
+	}
 }
 
-// Close closes the current database connection pool.
+// Close closes the current database connection pool and any cached
+// prepared statements.
 func (db *DB) Close() error {
+	if db.cache != nil {
+		db.cache.close()
+	}
 	return db.conn.Close()
 }
 
+// Stats returns the statistics of the database connection pool
+func (db *DB) Stats() sql.DBStats {
+	return db.conn.Stats()
+}
+
+// Strict returns a copy of the database connection pool whose results will
+// return an error instead of discarding selected columns that do not match
+// a destination struct field. Transactions begun by the copy are also
+// strict.
+func (db *DB) Strict() *DB {
+	strict := *db
+	strict.strict = true
+	return &strict
+}
+
+// TranslateErrors returns a copy of the database connection pool whose
+// errors will be translated by its dialect, if it is an ErrorTranslator,
+// into typed errors such as UniqueViolation. The driver error can still be
+// unwrapped with errors.As, but it will no longer match a type assertion.
+// Transactions begun by the copy also translate their errors.
+func (db *DB) TranslateErrors() *DB {
+	translated := *db
+	translated.middleware.translate = true
+	return &translated
+}
+
+// WithConverter returns a copy of the database connection pool that will
+// convert parameters and scan destinations of the type of the given value
+// using the converter. Transactions begun by the copy also use the
+// converter. See RegisterConverter to add a converter to every connection,
+// which is required for struct types. It panics if given a struct type
+// whose fields would otherwise be selected.
+func (db *DB) WithConverter(v interface{}, c Converter) *DB {
+	converted := *db
+	converted.converters = db.converters.with(v, c)
+	return &converted
+}
+
+// WithHook returns a copy of the database connection pool that will call
+// the given hook after every statement it executes. Transactions begun by
+// the copy also call the hook.
+//
+//  conn = conn.WithHook(aspect.SlowQueryLogger(nil, time.Second))
+//
+func (db *DB) WithHook(hook Hook) *DB {
+	hooked := *db
+	hooked.middleware = db.middleware.withHook(hook)
+	return &hooked
+}
+
+// WithInterceptor returns a copy of the database connection pool that will
+// call the given interceptor before every statement it executes.
+// Transactions begun by the copy also call the interceptor.
+func (db *DB) WithInterceptor(interceptor Interceptor) *DB {
+	intercepted := *db
+	intercepted.middleware = db.middleware.withInterceptor(interceptor)
+	return &intercepted
+}
+
 // Dialect returns the dialect associated with the current database connection
 // pool.
 func (db *DB) Dialect() Dialect {
@@ -80,6 +184,31 @@ func (db *DB) compile(stmt Executable) (string, *Parameters, error) {
 	return s, params, err
 }
 
+// execer returns the cache of prepared statements, if there is one, or
+// the connection pool
+func (db *DB) execer() execer {
+	if db.cache != nil {
+		return cachedExecer{cache: db.cache}
+	}
+	return db.conn
+}
+
+// exec executes the compiled statement through the hooks and interceptors
+// of the connection pool
+func (db *DB) exec(stmt Executable, s string, args []interface{}) (sql.Result, error) {
+	return db.middleware.exec(db.execer(), Statement{
+		Stmt: stmt, SQL: s, Args: args, Dialect: db.dialect,
+	})
+}
+
+// query queries the compiled statement through the hooks and interceptors
+// of the connection pool. The queried SQL is also returned.
+func (db *DB) query(stmt Executable, s string, args []interface{}) (*sql.Rows, string, error) {
+	return db.middleware.query(db.execer(), Statement{
+		Stmt: stmt, SQL: s, Args: args, Dialect: db.dialect,
+	})
+}
+
 // Execute executes the Executable statement with optional arguments. It
 // returns the database/sql package's Result object, which may contain
 // information on rows affected and last ID inserted depending on the driver.
@@ -90,10 +219,10 @@ func (db *DB) Execute(stmt Executable, args ...interface{}) (sql.Result, error)
 	}
 
 	// Use any arguments given to Execute() over compiled arguments
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(db.converters, params.args, args); err != nil {
+		return nil, err
 	}
-	return db.conn.Exec(s, args...)
+	return db.exec(stmt, s, args)
 }
 
 // Query executes an Executable statement with the optional arguments. It
@@ -105,16 +234,16 @@ func (db *DB) Query(stmt Executable, args ...interface{}) (*Result, error) {
 	}
 
 	// Use any arguments given to Query() over compiled arguments
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(db.converters, params.args, args); err != nil {
+		return nil, err
 	}
 
-	rows, err := db.conn.Query(s, args...)
+	rows, s, err := db.query(stmt, s, args)
 	if err != nil {
 		return nil, err
 	}
 	// Wrap the sql rows in a result
-	return &Result{rows: rows, stmt: s}, nil
+	return newResult(rows, s, args, stmt, db.strict, db.converters), nil
 }
 
 // QueryAll will query the statement and populate the given interface with all
@@ -159,7 +288,7 @@ func (db *DB) MustBegin() Transaction {
 			err,
 		)
 	}
-	return &TX{Tx: tx, dialect: db.dialect}
+	return db.wrapTx(tx)
 }
 
 // MustExecute will panic on error.
@@ -172,10 +301,14 @@ func (db *DB) MustExecute(stmt Executable, args ...interface{}) sql.Result {
 			err,
 		)
 	}
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(db.converters, params.args, args); err != nil {
+		log.Panicf(
+			"aspect: failed to bind parameters of (%s): %s",
+			s,
+			err,
+		)
 	}
-	result, err := db.conn.Exec(s, args...)
+	result, err := db.exec(stmt, s, args)
 	if err != nil {
 		log.Panicf(
 			"aspect: failed to exec (%s) with parameters (%v): %s",
@@ -199,11 +332,15 @@ func (db *DB) MustQuery(stmt Executable, args ...interface{}) *Result {
 	}
 
 	// Use any arguments given to Query() over compiled arguments
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(db.converters, params.args, args); err != nil {
+		log.Panicf(
+			"aspect: failed to bind parameters of (%s): %s",
+			s,
+			err,
+		)
 	}
 
-	rows, err := db.conn.Query(s, args...)
+	rows, s, err := db.query(stmt, s, args)
 	if err != nil {
 		log.Panicf(
 			"aspect: failed to query (%s) with parameters (%v): %s",
@@ -213,17 +350,17 @@ func (db *DB) MustQuery(stmt Executable, args ...interface{}) *Result {
 		)
 	}
 	// Wrap the sql rows in a result
-	return &Result{rows: rows, stmt: s}
+	return newResult(rows, s, args, stmt, db.strict, db.converters)
 }
 
 // MustQueryAll
 func (db *DB) MustQueryAll(stmt Executable, i interface{}) {
 	result := db.MustQuery(stmt)
 	if err := result.All(i); err != nil {
-		// TODO get parameters from result?
 		log.Panicf(
-			"aspect: failed to query all (%s): %s",
+			"aspect: failed to query all (%s) with parameters (%v): %s",
 			result.stmt,
+			result.args,
 			err,
 		)
 	}
@@ -240,10 +377,10 @@ func (db *DB) MustQueryOne(stmt Executable, i interface{}) bool {
 	if err == ErrNoResult {
 		return false
 	} else if err != nil {
-		// TODO get parameters from result?
 		log.Panicf(
-			"aspect: failed to query one (%s): %s",
+			"aspect: failed to query one (%s) with parameters (%v): %s",
 			result.stmt,
+			result.args,
 			err,
 		)
 	}
@@ -270,7 +407,61 @@ func Connect(driver, credentials string) (*DB, error) {
 // the transaction.
 type TX struct {
 	*sql.Tx
-	dialect Dialect
+	dialect    Dialect
+	strict     bool
+	converters Converters
+	middleware middleware
+	cache      *stmtCache
+	savepoint  string // The savepoint of a nested transaction
+	savepoints *int   // The number of savepoints created, shared by nesting
+}
+
+// Strict returns a copy of the transaction whose results will return an
+// error instead of discarding selected columns that do not match a
+// destination struct field.
+func (tx *TX) Strict() *TX {
+	strict := *tx
+	strict.strict = true
+	return &strict
+}
+
+// TranslateErrors returns a copy of the transaction whose errors will be
+// translated by its dialect into typed errors, as with DB.TranslateErrors.
+func (tx *TX) TranslateErrors() *TX {
+	translated := *tx
+	translated.middleware.translate = true
+	return &translated
+}
+
+// WithConverter returns a copy of the transaction that will convert
+// parameters and scan destinations of the type of the given value using
+// the converter. As with DB.WithConverter, struct types must be registered
+// with RegisterConverter instead.
+func (tx *TX) WithConverter(v interface{}, c Converter) *TX {
+	converted := *tx
+	converted.converters = tx.converters.with(v, c)
+	return &converted
+}
+
+// WithHook returns a copy of the transaction that will call the given hook
+// after every statement it executes.
+func (tx *TX) WithHook(hook Hook) *TX {
+	hooked := *tx
+	hooked.middleware = tx.middleware.withHook(hook)
+	return &hooked
+}
+
+// WithInterceptor returns a copy of the transaction that will call the
+// given interceptor before every statement it executes.
+func (tx *TX) WithInterceptor(interceptor Interceptor) *TX {
+	intercepted := *tx
+	intercepted.middleware = tx.middleware.withInterceptor(interceptor)
+	return &intercepted
+}
+
+// Dialect returns the dialect associated with the current transaction.
+func (tx *TX) Dialect() Dialect {
+	return tx.dialect
 }
 
 func (tx *TX) compile(stmt Executable) (string, *Parameters, error) {
@@ -282,32 +473,89 @@ func (tx *TX) compile(stmt Executable) (string, *Parameters, error) {
 	return s, params, err
 }
 
-// Begin returns the existing transaction. TODO Are nested transactions
-// possible? And on what dialects?
+// execer returns the cache of prepared statements, if there is one, or
+// the transaction
+func (tx *TX) execer() execer {
+	if tx.cache != nil {
+		return cachedExecer{cache: tx.cache, tx: tx.Tx}
+	}
+	return tx.Tx
+}
+
+// exec executes the compiled statement through the hooks and interceptors
+// of the transaction
+func (tx *TX) exec(stmt Executable, s string, args []interface{}) (sql.Result, error) {
+	return tx.middleware.exec(tx.execer(), Statement{
+		Stmt: stmt, SQL: s, Args: args, Dialect: tx.dialect, InTransaction: true,
+	})
+}
+
+// query queries the compiled statement through the hooks and interceptors
+// of the transaction. The queried SQL is also returned.
+func (tx *TX) query(stmt Executable, s string, args []interface{}) (*sql.Rows, string, error) {
+	return tx.middleware.query(tx.execer(), Statement{
+		Stmt: stmt, SQL: s, Args: args, Dialect: tx.dialect, InTransaction: true,
+	})
+}
+
+// Begin starts a nested transaction with a SAVEPOINT in the current
+// transaction. Committing the nested transaction releases the savepoint and
+// rolling it back rolls back to the savepoint, leaving the current
+// transaction open. Code that begins a transaction can therefore be called
+// with either a DB or a TX.
 func (tx *TX) Begin() (Transaction, error) {
-	return tx, nil
+	if tx.savepoints == nil {
+		tx.savepoints = new(int)
+	}
+	*tx.savepoints += 1
+	nested := *tx
+	nested.savepoint = fmt.Sprintf("aspect_savepoint_%d", *tx.savepoints)
+	if err := nested.execSavepoint("SAVEPOINT %s"); err != nil {
+		return nil, err
+	}
+	return &nested, nil
 }
 
-// Commit calls the wrapped transactions Commit method.
+// execSavepoint executes the given savepoint statement format with the name
+// of the transaction's savepoint. Prepared statements are not used, since
+// not every database can prepare savepoint statements.
+func (tx *TX) execSavepoint(format string) error {
+	s := fmt.Sprintf(format, tx.savepoint)
+	_, err := tx.middleware.exec(tx.Tx, Statement{
+		Stmt: Raw(s), SQL: s, Dialect: tx.dialect, InTransaction: true,
+	})
+	return err
+}
+
+// Commit calls the wrapped transactions Commit method, or releases the
+// savepoint of a nested transaction. If the transaction translates errors,
+// errors of deferred constraints are translated by the dialect.
 func (tx *TX) Commit() error {
-	return tx.Tx.Commit()
+	if tx.savepoint != "" {
+		return tx.execSavepoint("RELEASE SAVEPOINT %s")
+	}
+	err := tx.Tx.Commit()
+	if tx.middleware.translate {
+		err = translateError(tx.dialect, nil, err)
+	}
+	return err
 }
 
 func (tx *TX) CommitIf(commit *bool) error {
 	if *commit {
-		return tx.Tx.Commit()
+		return tx.Commit()
 	} else {
-		return tx.Tx.Rollback()
+		return tx.Rollback()
 	}
 }
 
 func (tx *TX) MustCommitIf(commit *bool) bool {
 	if *commit {
-		if err := tx.Tx.Commit(); err != nil {
+		if err := tx.Commit(); err != nil {
 			log.Panicf("aspect: error during commit: %s", err)
 		}
 	} else {
-		if err := tx.Tx.Rollback(); err != nil {
+		if err := tx.Rollback(); err != nil {
 			log.Panicf("aspect: error during rollback: %s", err)
 		}
 	}
@@ -316,7 +564,7 @@ func (tx *TX) MustCommitIf(commit *bool) bool {
 
 func (tx *TX) MustRollbackIf(rollback *bool) {
 	if *rollback {
-		if err := tx.Tx.Rollback(); err != nil {
+		if err := tx.Rollback(); err != nil {
 			log.Panicf("aspect: error during rollback: %s", err)
 		}
 	}
@@ -333,10 +581,10 @@ func (tx *TX) Execute(stmt Executable, args ...interface{}) (sql.Result, error)
 	}
 
 	// Use any arguments given to Query() over compiled arguments
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(tx.converters, params.args, args); err != nil {
+		return nil, err
 	}
-	return tx.Exec(s, args...)
+	return tx.exec(stmt, s, args)
 }
 
 // Query executes an Executable statement with the optional arguments
@@ -349,16 +597,16 @@ func (tx *TX) Query(stmt Executable, args ...interface{}) (*Result, error) {
 	}
 
 	// Use any arguments given to Query() over compiled arguments
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(tx.converters, params.args, args); err != nil {
+		return nil, err
 	}
 
-	rows, err := tx.Tx.Query(s, args...)
+	rows, s, err := tx.query(stmt, s, args)
 	if err != nil {
 		return nil, err
 	}
 	// Wrap the sql rows in a result
-	return &Result{rows: rows, stmt: s}, nil
+	return newResult(rows, s, args, stmt, tx.strict, tx.converters), nil
 }
 
 // QueryAll will query the statement using the current transaction and
@@ -383,9 +631,17 @@ func (tx *TX) QueryOne(stmt Executable, i interface{}) error {
 	return result.One(i)
 }
 
-// Begin returns the existing transaction.
+// MustBegin starts a nested transaction with a SAVEPOINT in the current
+// transaction. It will panic on error.
 func (tx *TX) MustBegin() Transaction {
-	return tx
+	nested, err := tx.Begin()
+	if err != nil {
+		log.Panicf(
+			"aspect: failed to begin nested transaction: %s",
+			err,
+		)
+	}
+	return nested
 }
 
 // MustExecute will panic on error.
@@ -398,10 +654,14 @@ func (tx *TX) MustExecute(stmt Executable, args ...interface{}) sql.Result {
 			err,
 		)
 	}
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(tx.converters, params.args, args); err != nil {
+		log.Panicf(
+			"aspect: failed to bind parameters of (%s): %s",
+			s,
+			err,
+		)
 	}
-	result, err := tx.Exec(s, args...)
+	result, err := tx.exec(stmt, s, args)
 	if err != nil {
 		log.Panicf(
 			"aspect: failed to exec (%s) with parameters (%v): %s",
@@ -425,11 +685,15 @@ func (tx *TX) MustQuery(stmt Executable, args ...interface{}) *Result {
 	}
 
 	// Use any arguments given to Query() over compiled arguments
-	if len(args) == 0 {
-		args = params.args
+	if args, err = bindArgs(tx.converters, params.args, args); err != nil {
+		log.Panicf(
+			"aspect: failed to bind parameters of (%s): %s",
+			s,
+			err,
+		)
 	}
 
-	rows, err := tx.Tx.Query(s, args...)
+	rows, s, err := tx.query(stmt, s, args)
 	if err != nil {
 		log.Panicf(
 			"aspect: failed to query (%s) with parameters (%v): %s",
@@ -439,17 +703,17 @@ func (tx *TX) MustQuery(stmt Executable, args ...interface{}) *Result {
 		)
 	}
 	// Wrap the sql rows in a result
-	return &Result{rows: rows, stmt: s}
+	return newResult(rows, s, args, stmt, tx.strict, tx.converters)
 }
 
 // MustQueryAll
 func (tx *TX) MustQueryAll(stmt Executable, i interface{}) {
 	result := tx.MustQuery(stmt)
 	if err := result.All(i); err != nil {
-		// TODO get parameters from result?
 		log.Panicf(
-			"aspect: failed to query all (%s): %s",
+			"aspect: failed to query all (%s) with parameters (%v): %s",
 			result.stmt,
+			result.args,
 			err,
 		)
 	}
@@ -466,18 +730,25 @@ func (tx *TX) MustQueryOne(stmt Executable, i interface{}) bool {
 	if err == ErrNoResult {
 		return false
 	} else if err != nil {
-		// TODO get parameters from result?
 		log.Panicf(
-			"aspect: failed to query one (%s): %s",
+			"aspect: failed to query one (%s) with parameters (%v): %s",
 			result.stmt,
+			result.args,
 			err,
 		)
 	}
 	return true
 }
 
-// Rollback calls the wrapped transactions Rollback method.
+// Rollback calls the wrapped transactions Rollback method, or rolls back
+// to and releases the savepoint of a nested transaction.
 func (tx *TX) Rollback() error {
+	if tx.savepoint != "" {
+		if err := tx.execSavepoint("ROLLBACK TO SAVEPOINT %s"); err != nil {
+			return err
+		}
+		return tx.execSavepoint("RELEASE SAVEPOINT %s")
+	}
 	return tx.Tx.Rollback()
 }
 
@@ -494,19 +765,24 @@ func (tx *TX) String(stmt Executable) string {
 // WrapTx allows aspect to take control of an existing database/sql
 // transaction and execute queries using the given dialect.
 func WrapTx(tx *sql.Tx, dialect Dialect) *TX {
-	return &TX{Tx: tx, dialect: dialect}
+	return &TX{Tx: tx, dialect: dialect, savepoints: new(int)}
 }
 
 type fakeTX struct {
 	tx Transaction
 }
 
+// MustBegin begins a nested transaction of the wrapped transaction, which
+// will be a savepoint if it is a TX
 func (tx *fakeTX) MustBegin() Transaction {
-	return tx
+	return tx.tx.MustBegin()
 }
 
+// Begin begins a nested transaction of the wrapped transaction, which will
+// be a savepoint if it is a TX. Only the Commit and Rollback of the fake
+// transaction itself do nothing.
 func (tx *fakeTX) Begin() (Transaction, error) {
-	return tx, nil
+	return tx.tx.Begin()
 }
 
 func (tx *fakeTX) Commit() error {
@@ -523,6 +799,11 @@ func (tx *fakeTX) MustCommitIf(commit *bool) bool {
 
 func (tx *fakeTX) MustRollbackIf(rollback *bool) {}
 
+func (tx *fakeTX) Dialect() Dialect {
+	d, _ := ConnectionDialect(tx.tx)
+	return d
+}
+
 func (tx *fakeTX) Execute(stmt Executable, args ...interface{}) (sql.Result, error) {
 	return tx.tx.Execute(stmt, args...)
 }
@@ -560,11 +841,11 @@ func (tx *fakeTX) Rollback() error {
 }
 
 func (tx *fakeTX) String(stmt Executable) string {
-	return tx.String(stmt)
+	return tx.tx.String(stmt)
 }
 
 // FakeTx allows testing of transactional blocks of code. Commit and Rollback
-// do nothing.
+// do nothing, but nested transactions begun with Begin are real.
 func FakeTx(tx Transaction) *fakeTX {
 	return &fakeTX{tx: tx}
 }
@@ -0,0 +1,37 @@
+package aspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTX_Begin(t *testing.T) {
+	db, d := openCounting(t, "counting_savepoints")
+	defer db.Close()
+
+	tx := db.MustBegin()
+	nested := tx.MustBegin()
+	nested.MustExecute(users.Delete())
+	require.Nil(t, nested.Commit())
+
+	commit := false
+	again := tx.MustBegin()
+	inner := again.MustBegin()
+	assert.False(t, inner.MustCommitIf(&commit))
+	require.Nil(t, again.Rollback())
+	require.Nil(t, tx.Commit())
+
+	assert.Equal(t, []string{
+		`SAVEPOINT aspect_savepoint_1`,
+		`DELETE FROM "users"`,
+		`RELEASE SAVEPOINT aspect_savepoint_1`,
+		`SAVEPOINT aspect_savepoint_2`,
+		`SAVEPOINT aspect_savepoint_3`,
+		`ROLLBACK TO SAVEPOINT aspect_savepoint_3`,
+		`RELEASE SAVEPOINT aspect_savepoint_3`,
+		`ROLLBACK TO SAVEPOINT aspect_savepoint_2`,
+		`RELEASE SAVEPOINT aspect_savepoint_2`,
+	}, d.prepared)
+}
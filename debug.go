@@ -0,0 +1,101 @@
+package aspect
+
+// literalDialect wraps a dialect, outputting escaped literals of the given
+// arguments in place of parameters
+type literalDialect struct {
+	Dialect
+	args []interface{}
+}
+
+// Parameterize outputs the literal of the argument at the given index.
+// Bound parameters without a value are output by name and values that
+// cannot be output as literals keep their parameter.
+func (d literalDialect) Parameterize(i int) string {
+	if i < 1 || i > len(d.args) {
+		return d.Dialect.Parameterize(i)
+	}
+	if bound, ok := d.args[i-1].(BindClause); ok {
+		return ":" + bound.name
+	}
+	literal, err := CompileLiteral(d.Dialect, d.args[i-1])
+	if err != nil {
+		return d.Dialect.Parameterize(i)
+	}
+	return literal
+}
+
+// The optional interfaces of the wrapped dialect are forwarded explicitly,
+// since embedding only forwards the methods of Dialect. Each falls back to
+// the behavior of a dialect that does not implement the interface.
+
+// EscapeString applies any additional escaping of the wrapped dialect
+func (d literalDialect) EscapeString(s string) string {
+	if escaper, ok := d.Dialect.(Escaper); ok {
+		return escaper.EscapeString(s)
+	}
+	return s
+}
+
+// CanAlterColumn reports whether the wrapped dialect can perform the
+// given column alteration
+func (d literalDialect) CanAlterColumn(alteration ColumnAlteration) bool {
+	if alterer, ok := d.Dialect.(ColumnAlterer); ok {
+		return alterer.CanAlterColumn(alteration)
+	}
+	return true
+}
+
+// Name returns the name of the wrapped dialect
+func (d literalDialect) Name() string {
+	return DialectName(d.Dialect)
+}
+
+// TranslateError translates the error with the wrapped dialect
+func (d literalDialect) TranslateError(err error) error {
+	if translator, ok := d.Dialect.(ErrorTranslator); ok {
+		return translator.TranslateError(err)
+	}
+	return err
+}
+
+// Debug outputs the statement in the given dialect with escaped literals in
+// place of its parameters, which is intended for logs and for pasting into
+// a database shell. Any arguments replace the compiled parameters as they
+// would during execution, including Args for bound parameters. If an error
+// occurred during compilation, then its string output will be returned.
+//
+//  stmt := Users.Delete().Where(Users.C["name"].Equals("admin"))
+//  log.Println(aspect.Debug(stmt, &postgres.PostGres{}))
+//  // DELETE FROM "users" WHERE "users"."name" = 'admin'
+//
+// The output must never be executed in place of the parameterized
+// statement.
+func Debug(stmt Executable, d Dialect, args ...interface{}) string {
+	params := Params()
+	if _, err := stmt.Compile(d, params); err != nil {
+		return err.Error()
+	}
+
+	// Unlike execution, bound parameters may remain without values
+	var named Args
+	if len(args) == 1 {
+		named, _ = args[0].(Args)
+	}
+	if named != nil {
+		for i, arg := range params.args {
+			if bound, ok := arg.(BindClause); ok {
+				if value, exists := named[bound.name]; exists {
+					params.args[i] = value
+				}
+			}
+		}
+	} else if len(args) > 0 {
+		params.args = args
+	}
+
+	compiled, err := stmt.Compile(literalDialect{Dialect: d, args: params.args}, Params())
+	if err != nil {
+		return err.Error()
+	}
+	return compiled
+}
@@ -0,0 +1,83 @@
+package aspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebug(t *testing.T) {
+	stmt := users.Select().Where(
+		users.C["name"].Equals("O'Brien"),
+		users.C["id"].In([]int64{1, 2}),
+	)
+	assert.Equal(t,
+		`SELECT "users"."id", "users"."name", "users"."password" FROM "users" WHERE ("users"."name" = 'O''Brien' AND "users"."id" IN (1, 2))`,
+		Debug(stmt, &defaultDialect{}),
+	)
+
+	// The additional escaping of the dialect is used
+	assert.Equal(t,
+		`DELETE FROM "users" WHERE "users"."name" = 'a!'`,
+		Debug(users.Delete().Where(users.C["name"].Equals("a")), &escapeDialect{}),
+	)
+
+	// Arguments replace the compiled parameters
+	insert := users.Insert().Values(Values{"id": Bind("id"), "name": nil, "password": "x"})
+	assert.Equal(t,
+		`INSERT INTO "users" ("id", "name", "password") VALUES (:id, NULL, 'x')`,
+		Debug(insert, &defaultDialect{}),
+	)
+	assert.Equal(t,
+		`INSERT INTO "users" ("id", "name", "password") VALUES (3, NULL, 'x')`,
+		Debug(insert, &defaultDialect{}, Args{"id": 3}),
+	)
+	assert.Equal(t,
+		`INSERT INTO "users" ("id", "name", "password") VALUES (4, 'a', TRUE)`,
+		Debug(insert, &defaultDialect{}, 4, "a", true),
+	)
+
+	// Values without literals keep their parameter
+	assert.Equal(t,
+		`DELETE FROM "users" WHERE "users"."id" = $1`,
+		Debug(users.Delete().Where(users.C["id"].Equals(struct{}{})), &defaultDialect{}),
+	)
+
+	// Compilation errors are output
+	assert.Equal(t,
+		"aspect: args cannot be set by empty slices",
+		Debug(users.Insert().Values([]Values{}), &defaultDialect{}),
+	)
+}
+
+// fixedDialect cannot alter the type or NOT NULL constraint of columns
+type fixedDialect struct {
+	defaultDialect
+}
+
+func (d *fixedDialect) CanAlterColumn(alteration ColumnAlteration) bool {
+	return alteration == AlterDefault
+}
+
+func TestDebug_Alter(t *testing.T) {
+	// Debug output should match compilation with the dialect itself
+	stmts := []AlterTableStmt{
+		users.Alter().SetNotNull("name"),
+		users.Alter().AlterColumnType("name", Text{}),
+		users.Alter().SetDefault("name", "admin"),
+	}
+	d := &fixedDialect{}
+	for _, stmt := range stmts {
+		compiled, err := stmt.Compile(d, Params())
+		if err != nil {
+			compiled = err.Error()
+		}
+		assert.Equal(t, compiled, Debug(stmt, d))
+	}
+	assert.Contains(t, Debug(stmts[0], d), "cannot alter")
+
+	var _ ColumnAlterer = literalDialect{Dialect: d}
+	var _ Namer = literalDialect{Dialect: d}
+	var _ ErrorTranslator = literalDialect{Dialect: d}
+	assert.Equal(t, "named", literalDialect{Dialect: &namedDialect{}}.Name())
+}
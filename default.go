@@ -0,0 +1,54 @@
+package aspect
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Defaulter is implemented by types that may have a column DEFAULT. The
+// insert machinery uses it to determine which columns can be omitted.
+type Defaulter interface {
+	HasDefault() bool
+}
+
+// Now returns a clause for the current date and time. It is supported by
+// all included dialects.
+func Now() Clause {
+	return Raw(`CURRENT_TIMESTAMP`)
+}
+
+// HasDefaultValue returns true if the given value of a type's Default field
+// should produce a DEFAULT clause. Nil values and nil pointers do not.
+func HasDefaultValue(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	v := reflect.ValueOf(value)
+	return !(v.Kind() == reflect.Ptr && v.IsNil())
+}
+
+// CompileDefault outputs the DEFAULT clause for the given value using the
+// given dialect. Clauses will be compiled and wrapped in parentheses,
+// while all other values, including strings and those given to Literal,
+// will be output as escaped literals. SQL expressions such as now() must
+// be given as a Clause. An empty string is returned if the value has no
+// default.
+func CompileDefault(d Dialect, value interface{}) (string, error) {
+	if !HasDefaultValue(value) {
+		return "", nil
+	}
+	if literal, ok := value.(LiteralClause); ok {
+		value = literal.Value
+	} else if clause, ok := value.(Clause); ok {
+		compiled, err := clause.Compile(d, Params())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("DEFAULT (%s)", compiled), nil
+	}
+	compiled, err := CompileLiteral(d, value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("DEFAULT %s", compiled), nil
+}
@@ -19,6 +19,11 @@ func (stmt DeleteStmt) String() string {
 	return compiled
 }
 
+// Table returns the table of this statement
+func (stmt DeleteStmt) Table() *TableElem {
+	return stmt.table
+}
+
 // Compile outputs the DELETE statement using the given dialect and parameters.
 // An error may be returned because of a pre-existing error or because
 // an error occurred during compilation.
@@ -26,7 +31,7 @@ func (stmt DeleteStmt) Compile(d Dialect, params *Parameters) (string, error) {
 	if err := stmt.Error(); err != nil {
 		return "", err
 	}
-	compiled := fmt.Sprintf(`DELETE FROM "%s"`, stmt.table.Name())
+	compiled := fmt.Sprintf(`DELETE FROM %s`, stmt.table.Compile(d, params))
 
 	if stmt.cond != nil {
 		cc, err := stmt.cond.Compile(d, params)
@@ -0,0 +1,214 @@
+package aspect
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag options used by TableFromStruct, such as `db:"email,unique,length=256"`
+const (
+	pkOption      = "pk"
+	uniqueOption  = "unique"
+	notNullOption = "notnull"
+	nullOption    = "null"
+	lengthOption  = "length="
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+)
+
+// TableFromStruct creates a table whose columns are derived from the fields
+// of the given struct, which may also be a pointer to a struct. Fields are
+// named and skipped according to their db tags, as in SelectFields.
+//
+// Column types are chosen from the Go type of each field:
+//
+//  int, int64, uint32, uint64    BigInt
+//  int8, int16, int32, uint8...  Integer
+//  float32                       Real
+//  float64, sql.NullFloat64      Double
+//  string, sql.NullString        String
+//  bool, sql.NullBool            Boolean
+//  time.Time                     Timestamp
+//  sql.NullInt64                 BigInt
+//
+// Columns are NOT NULL unless their field is a pointer or an sql.Null type.
+// The following tag options are also supported:
+//
+//  pk        part of the primary key
+//  unique    has a single column unique constraint
+//  notnull   always NOT NULL
+//  null      never NOT NULL
+//  length=N  the length of a String
+//
+// Any additional elements are applied after the derived columns. Columns
+// and foreign keys given as elements replace the derived column of the same
+// name, which allows types such as postgres.Serial to be used:
+//
+//  var Users = aspect.TableFromStruct("users", User{},
+//      aspect.Column("id", postgres.Serial{}),
+//  )
+//
+// As with Table, it will panic on error.
+func TableFromStruct(name string, v interface{}, elements ...TableModifier) *TableElem {
+	table, err := newTableFromStruct(name, v, elements...)
+	if err != nil {
+		log.Panic(err)
+	}
+	return table
+}
+
+func newTableFromStruct(name string, v interface{}, elements ...TableModifier) (*TableElem, error) {
+	elem := reflect.TypeOf(v)
+	if elem != nil && elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem == nil || elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf(
+			"aspect: tables can only be derived from structs, received %T", v,
+		)
+	}
+
+	// Columns given as elements replace derived columns
+	overrides := make(map[string]TableModifier)
+	var others []TableModifier
+	for _, element := range elements {
+		if column, ok := columnName(element); ok {
+			overrides[column] = element
+		} else {
+			others = append(others, element)
+		}
+	}
+
+	var columns []TableModifier
+	var pk []string
+	var uniques []TableModifier
+	for _, field := range SelectFieldsFromElem(elem) {
+		// Skip fields qualified with the name of another table
+		if field.table != "" && field.table != name {
+			continue
+		}
+		override, overridden := overrides[field.column]
+		// An override that is itself a primary key replaces the pk option
+		if field.HasOption(pkOption) && !(overridden && isPrimaryKey(override)) {
+			pk = append(pk, field.column)
+		}
+		if field.HasOption(uniqueOption) {
+			uniques = append(uniques, Unique(field.column))
+		}
+		if overridden {
+			columns = append(columns, override)
+			delete(overrides, field.column)
+			continue
+		}
+		typ, err := deriveType(elem.FieldByIndex(field.index).Type, field.options)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"aspect: cannot derive the column %s: %s", field.column, err,
+			)
+		}
+		columns = append(columns, Column(field.column, typ))
+	}
+
+	// Remaining overrides are columns without a matching field
+	for _, element := range elements {
+		if column, ok := columnName(element); ok && overrides[column] != nil {
+			columns = append(columns, element)
+		}
+	}
+	if len(pk) > 0 {
+		columns = append(columns, PrimaryKey(pk...))
+	}
+	columns = append(columns, uniques...)
+	return newTable(name, append(columns, others...)...)
+}
+
+// isPrimaryKey returns true if the given element declares a column whose
+// type is a primary key
+func isPrimaryKey(element TableModifier) bool {
+	typed, ok := element.(interface {
+		Type() Type
+	})
+	return ok && typed.Type() != nil && typed.Type().IsPrimaryKey()
+}
+
+// columnName returns the name of the given element if it declares a column
+func columnName(element TableModifier) (string, bool) {
+	switch e := element.(type) {
+	case ColumnElem:
+		return e.Name(), true
+	case ForeignKeyElem:
+		return e.Name(), true
+	case SelfForeignKeyElem:
+		return e.Name(), true
+	}
+	return "", false
+}
+
+// deriveType returns the column type of the given Go type and tag options
+func deriveType(t reflect.Type, opts options) (Type, error) {
+	notNull := true
+	if t.Kind() == reflect.Ptr {
+		notNull = false
+		t = t.Elem()
+	}
+	switch t {
+	case nullStringType, nullInt64Type, nullFloat64Type, nullBoolType:
+		notNull = false
+	}
+	if opts.Has(notNullOption) || opts.Has(pkOption) {
+		notNull = true
+	} else if opts.Has(nullOption) {
+		notNull = false
+	}
+
+	var length int
+	for _, opt := range opts {
+		if strings.HasPrefix(opt, lengthOption) {
+			var err error
+			length, err = strconv.Atoi(strings.TrimPrefix(opt, lengthOption))
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid length option %s", opt)
+			}
+		}
+	}
+
+	switch t {
+	case timeType:
+		return Timestamp{NotNull: notNull}, nil
+	case nullStringType:
+		return String{Length: length, NotNull: notNull}, nil
+	case nullInt64Type:
+		return BigInt{NotNull: notNull}, nil
+	case nullFloat64Type:
+		return Double{NotNull: notNull}, nil
+	case nullBoolType:
+		return Boolean{NotNull: notNull}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return BigInt{NotNull: notNull}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return Integer{NotNull: notNull}, nil
+	case reflect.Float32:
+		return Real{NotNull: notNull}, nil
+	case reflect.Float64:
+		return Double{NotNull: notNull}, nil
+	case reflect.String:
+		return String{Length: length, NotNull: notNull}, nil
+	case reflect.Bool:
+		return Boolean{NotNull: notNull}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
@@ -0,0 +1,84 @@
+package aspect
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type audit struct {
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+type account struct {
+	ID       int64          `db:"id,pk,omitempty"`
+	Email    string         `db:"email,unique,length=256"`
+	Name     sql.NullString `db:"name,length=64"`
+	Age      int32          `db:"age,null"`
+	Balance  float64        `db:"balance"`
+	Active   bool           `db:"active"`
+	ParentID sql.NullInt64  `db:"parent_id"`
+	Password string         `db:"-"`
+	audit
+}
+
+func TestTableFromStruct(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+
+	accounts := TableFromStruct("accounts", account{})
+	expect.SQL(`CREATE TABLE "accounts" (
+  "id" BIGINT NOT NULL,
+  "email" VARCHAR(256) NOT NULL,
+  "name" VARCHAR(64),
+  "age" INTEGER,
+  "balance" DOUBLE PRECISION NOT NULL,
+  "active" BOOLEAN NOT NULL,
+  "parent_id" BIGINT,
+  "created_at" TIMESTAMP NOT NULL,
+  "deleted_at" TIMESTAMP,
+  PRIMARY KEY ("id"),
+  UNIQUE ("email")
+);`, accounts.Create())
+
+	// Elements may replace derived columns and add constraints
+	accounts = TableFromStruct("accounts", &account{},
+		Schema("app"),
+		Column("id", Integer{PrimaryKey: true}),
+		SelfForeignKey("parent_id", "id", BigInt{}),
+		Column("notes", Text{}),
+	)
+	assert.Equal(t, "app", accounts.Schema())
+	assert.Equal(t, Integer{PrimaryKey: true}, accounts.C["id"].Type())
+	assert.Equal(t, 1, len(accounts.ForeignKeys()))
+	assert.Equal(t, 10, len(accounts.Columns()))
+	assert.Equal(t, "notes", accounts.Columns()[9].Name())
+	// The primary key of an override is not declared twice
+	expect.SQL(`CREATE TABLE "app"."accounts" (
+  "id" INTEGER PRIMARY KEY,
+  "email" VARCHAR(256) NOT NULL,
+  "name" VARCHAR(64),
+  "age" INTEGER,
+  "balance" DOUBLE PRECISION NOT NULL,
+  "active" BOOLEAN NOT NULL,
+  "parent_id" BIGINT REFERENCES "app"."accounts"("id"),
+  "created_at" TIMESTAMP NOT NULL,
+  "deleted_at" TIMESTAMP,
+  "notes" TEXT,
+  UNIQUE ("email")
+);`, accounts.Create())
+
+	// Only structs with supported types can be derived
+	_, err := newTableFromStruct("accounts", 1)
+	assert.NotNil(t, err)
+	_, err = newTableFromStruct("bad", struct {
+		Data []byte `db:"data"`
+	}{})
+	assert.NotNil(t, err)
+	_, err = newTableFromStruct("bad", struct {
+		Name string `db:"name,length=x"`
+	}{})
+	assert.NotNil(t, err)
+}
@@ -44,6 +44,29 @@ func GetDialect(name string) (Dialect, error) {
 	return d, nil
 }
 
+// Namer is an optional interface for dialects that report their own name.
+// Dialects without state should implement it, since pointers to zero-size
+// values may not be distinct and cannot reliably identify a registered
+// Dialect.
+type Namer interface {
+	Name() string
+}
+
+// DialectName returns the name of the given Dialect, if it is a Namer, or
+// the name it was registered with. An empty string is returned if neither
+// is known.
+func DialectName(d Dialect) string {
+	if namer, ok := d.(Namer); ok {
+		return namer.Name()
+	}
+	for name, dialect := range dialects {
+		if dialect == d {
+			return name
+		}
+	}
+	return ""
+}
+
 // MustGetDialect returns the Dialect in the registry with the given name.
 // It will panic if no Dialect with that name is found.
 func MustGetDialect(name string) Dialect {
@@ -27,4 +27,17 @@ func TestRegistry(t *testing.T) {
 
 	_, err = GetDialect("dne")
 	assert.NotNil(t, err, "Getting a dialect that does not exist should error")
+
+	// Dialect names
+	assert.Equal(t, "default", DialectName(MustGetDialect("default")))
+	assert.Equal(t, "named", DialectName(&namedDialect{}))
+}
+
+// namedDialect reports its own name
+type namedDialect struct {
+	defaultDialect
+}
+
+func (d *namedDialect) Name() string {
+	return "named"
 }
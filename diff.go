@@ -0,0 +1,241 @@
+package aspect
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GooseVersionLayout is the time layout used for the versions of goose
+// migration files.
+const GooseVersionLayout = "20060102150405"
+
+// Changes are the ordered statements needed to migrate a database from one
+// schema to another (Up) and back again (Down).
+type Changes struct {
+	Up   []Executable
+	Down []Executable
+}
+
+// Empty returns true if there are no changes between the schemas.
+func (c Changes) Empty() bool {
+	return len(c.Up) == 0 && len(c.Down) == 0
+}
+
+// WriteGoose writes the changes as a goose SQL migration using the given
+// dialect.
+func (c Changes) WriteGoose(w io.Writer, d Dialect) error {
+	if _, err := io.WriteString(w, "-- +goose Up\n"); err != nil {
+		return err
+	}
+	if err := writeStatements(w, d, c.Up); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\n-- +goose Down\n"); err != nil {
+		return err
+	}
+	return writeStatements(w, d, c.Down)
+}
+
+func writeStatements(w io.Writer, d Dialect, stmts []Executable) error {
+	for _, stmt := range stmts {
+		compiled, err := stmt.Compile(d, Params())
+		if err != nil {
+			return err
+		}
+		// Goose requires statements to end with a semicolon
+		if !strings.HasSuffix(compiled, ";") {
+			compiled += ";"
+		}
+		if _, err := fmt.Fprintln(w, compiled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GooseFilename returns the name of a goose SQL migration file for the
+// given version and name, such as 20150102150405_create_users.sql
+func GooseFilename(version time.Time, name string) string {
+	return fmt.Sprintf(
+		"%s_%s.sql", version.UTC().Format(GooseVersionLayout), name,
+	)
+}
+
+// Diff compares the current schema, such as one reflected from a live
+// database or a previous snapshot, with the target schema, such as the
+// tables declared in Go. It returns the statements needed to migrate
+// between them using the given dialect to compare column types.
+//
+// New tables are created in dependency order, then columns of existing
+// tables are added, altered and dropped, and finally removed tables are
+// dropped. Changes to the constraints of existing tables are not detected.
+// An error is returned if a column must be altered in a way the dialect
+// does not support, such as changing its type in sqlite3.
+func Diff(current, target *MetaData, d Dialect) (changes Changes, err error) {
+	if current == nil || target == nil {
+		err = fmt.Errorf("aspect: cannot diff a nil MetaData")
+		return
+	}
+	if changes.Up, err = migrate(current, target, d); err != nil {
+		return
+	}
+	changes.Down, err = migrate(target, current, d)
+	return
+}
+
+// migrate returns the statements required to migrate from one schema to
+// another.
+func migrate(from, to *MetaData, d Dialect) ([]Executable, error) {
+	fromSorted, err := from.Sorted()
+	if err != nil {
+		return nil, err
+	}
+	toSorted, err := to.Sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	var stmts []Executable
+	for _, table := range toSorted {
+		if from.Table(qualifiedName(table)) == nil {
+			stmts = append(stmts, table.Create())
+		}
+	}
+
+	for _, table := range toSorted {
+		previous := from.Table(qualifiedName(table))
+		if previous == nil {
+			continue
+		}
+		alters, err := alterColumns(previous, table, d)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, alters...)
+	}
+
+	// Drop tables in reverse dependency order
+	for i := len(fromSorted) - 1; i >= 0; i-- {
+		if to.Table(qualifiedName(fromSorted[i])) == nil {
+			stmts = append(stmts, fromSorted[i].Drop())
+		}
+	}
+	return stmts, nil
+}
+
+// alterColumns returns an ALTER TABLE statement for each change required
+// to migrate the columns of the previous table to those of the given table.
+// Each statement has a single action since some dialects allow no more.
+func alterColumns(previous, table *TableElem, d Dialect) ([]Executable, error) {
+	var stmts []Executable
+	for _, column := range table.Columns() {
+		old, exists := previous.C[column.Name()]
+		if !exists {
+			stmts = append(stmts, table.Alter().AddColumn(column))
+			continue
+		}
+
+		a, err := describeColumn(previous, old, d)
+		if err != nil {
+			return nil, err
+		}
+		b, err := describeColumn(table, column, d)
+		if err != nil {
+			return nil, err
+		}
+
+		name := column.Name()
+		if a.base != b.base {
+			if err := canAlterColumn(d, AlterType, name); err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, table.Alter().AlterColumnType(name, column.Type()))
+		}
+		if a.notNull != b.notNull {
+			if err := canAlterColumn(d, AlterNotNull, name); err != nil {
+				return nil, err
+			}
+			if b.notNull {
+				stmts = append(stmts, table.Alter().SetNotNull(name))
+			} else {
+				stmts = append(stmts, table.Alter().DropNotNull(name))
+			}
+		}
+		if a.def != b.def {
+			if err := canAlterColumn(d, AlterDefault, name); err != nil {
+				return nil, err
+			}
+			if b.def == "" {
+				stmts = append(stmts, table.Alter().DropDefault(name))
+			} else {
+				stmts = append(stmts, table.Alter().SetDefault(name, Raw(b.def)))
+			}
+		}
+	}
+
+	for _, column := range previous.Columns() {
+		if _, exists := table.C[column.Name()]; !exists {
+			stmts = append(stmts, table.Alter().DropColumn(column.Name()))
+		}
+	}
+	return stmts, nil
+}
+
+type columnDescription struct {
+	base    string
+	notNull bool
+	def     string
+}
+
+// casts matches trailing postgres casts, such as 'a'::character varying
+var casts = regexp.MustCompile(`::[a-z ]+(\[\])?$`)
+
+// describeColumn describes the given column in a normalized form so that
+// declared and reflected columns can be compared. Primary key columns are
+// always NOT NULL and the parentheses of clause defaults are removed.
+func describeColumn(table *TableElem, column ColumnElem, d Dialect) (desc columnDescription, err error) {
+	desc.base, desc.notNull, desc.def, err = describeType(d, column.Type())
+	if err != nil {
+		return
+	}
+	if column.Type().IsPrimaryKey() || table.pk.Contains(column.Name()) {
+		desc.notNull = true
+	}
+	desc.def = casts.ReplaceAllString(unwrap(desc.def), "")
+	return
+}
+
+// unwrap removes parentheses that enclose the whole of the given
+// expression, such as those of ((now())), but not those of (a) + (b)
+func unwrap(expr string) string {
+	for strings.HasPrefix(expr, "(") && closing(expr) == len(expr)-1 {
+		expr = expr[1 : len(expr)-1]
+	}
+	return expr
+}
+
+// closing returns the index of the parenthesis that closes the opening
+// parenthesis of the given expression, or -1 if it is never closed.
+// Parentheses within quoted strings are ignored.
+func closing(expr string) int {
+	var depth int
+	var quoted bool
+	for i, r := range expr {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth += 1
+		case r == ')':
+			depth -= 1
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,90 @@
+package aspect
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	// The previous schema
+	previous := NewMetaData()
+	Table("teams",
+		previous,
+		Column("id", Integer{PrimaryKey: true}),
+		Column("name", String{Length: 32}),
+		Column("city", String{}),
+	)
+	Table("stadiums",
+		previous,
+		Column("id", Integer{PrimaryKey: true}),
+	)
+
+	// The target schema
+	current := NewMetaData()
+	teams := Table("teams",
+		current,
+		Column("id", Integer{NotNull: true}),
+		Column("name", Text{NotNull: true}),
+		Column("founded", Integer{Default: 1900}),
+		PrimaryKey("id"),
+	)
+	Table("players",
+		current,
+		Column("id", Integer{PrimaryKey: true}),
+		ForeignKey("team_id", teams.C["id"], Integer{}),
+	)
+
+	changes, err := Diff(previous, current, &defaultDialect{})
+	require.Nil(t, err)
+	assert.False(t, changes.Empty())
+
+	var buffer bytes.Buffer
+	require.Nil(t, changes.WriteGoose(&buffer, &defaultDialect{}))
+	assert.Equal(t, `-- +goose Up
+CREATE TABLE "players" (
+  "id" INTEGER PRIMARY KEY,
+  "team_id" INTEGER REFERENCES teams("id")
+);
+ALTER TABLE "teams" ALTER COLUMN "name" TYPE TEXT;
+ALTER TABLE "teams" ALTER COLUMN "name" SET NOT NULL;
+ALTER TABLE "teams" ADD COLUMN "founded" INTEGER DEFAULT 1900;
+ALTER TABLE "teams" DROP COLUMN "city";
+DROP TABLE "stadiums";
+
+-- +goose Down
+CREATE TABLE "stadiums" (
+  "id" INTEGER PRIMARY KEY
+);
+ALTER TABLE "teams" ALTER COLUMN "name" TYPE VARCHAR(32);
+ALTER TABLE "teams" ALTER COLUMN "name" DROP NOT NULL;
+ALTER TABLE "teams" ADD COLUMN "city" VARCHAR;
+ALTER TABLE "teams" DROP COLUMN "founded";
+DROP TABLE "players";
+`, buffer.String())
+
+	// Identical schemas have no changes
+	changes, err = Diff(current, current, &defaultDialect{})
+	require.Nil(t, err)
+	assert.True(t, changes.Empty())
+
+	_, err = Diff(nil, current, &defaultDialect{})
+	assert.NotNil(t, err)
+
+	// Only parentheses that enclose the whole default are removed
+	assert.Equal(t, "now()", unwrap("((now()))"))
+	assert.Equal(t, "(a) + (b)", unwrap("(a) + (b)"))
+	assert.Equal(t, "(a) + (b)", unwrap("((a) + (b))"))
+	assert.Equal(t, "')' || a", unwrap("(')' || a)"))
+	assert.Equal(t, "(a", unwrap("(a"))
+
+	assert.Equal(t,
+		"20150102150405_create_teams.sql",
+		GooseFilename(
+			time.Date(2015, 1, 2, 15, 4, 5, 0, time.UTC), "create_teams",
+		),
+	)
+}
@@ -1,11 +1,23 @@
 package aspect
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // DropStmt is the internal representation of an DROP TABLE statement.
 type DropStmt struct {
-	table    *TableElem
+	Stmt
+	tables   []*TableElem
 	ifExists bool
+	behavior string
+}
+
+// Cascade adds the CASCADE modifier to a DROP TABLE statement, which will
+// also drop any objects that depend on the tables.
+func (stmt DropStmt) Cascade() DropStmt {
+	stmt.behavior = "CASCADE"
+	return stmt
 }
 
 // IfExists adds the IF EXISTS modifier to a DROP TABLE statement.
@@ -14,6 +26,13 @@ func (stmt DropStmt) IfExists() DropStmt {
 	return stmt
 }
 
+// Restrict adds the RESTRICT modifier to a DROP TABLE statement, which will
+// refuse to drop the tables if any objects depend on them.
+func (stmt DropStmt) Restrict() DropStmt {
+	stmt.behavior = "RESTRICT"
+	return stmt
+}
+
 // String outputs the parameter-less CREATE TABLE statement in a neutral
 // dialect.
 func (stmt DropStmt) String() string {
@@ -25,8 +44,43 @@ func (stmt DropStmt) String() string {
 // parameters. An error may be returned because of a pre-existing error or
 // because an error occurred during compilation.
 func (stmt DropStmt) Compile(d Dialect, p *Parameters) (string, error) {
+	if err := stmt.Error(); err != nil {
+		return "", err
+	}
+
+	compiled := "DROP TABLE"
 	if stmt.ifExists {
-		return fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, stmt.table.Name()), nil
+		compiled += " IF EXISTS"
+	}
+	compiled += fmt.Sprintf(" %s", compileTables(stmt.tables, d, p))
+	if stmt.behavior != "" {
+		compiled += fmt.Sprintf(" %s", stmt.behavior)
+	}
+	return compiled, nil
+}
+
+// compileTables joins the compiled names of the given tables.
+func compileTables(tables []*TableElem, d Dialect, p *Parameters) string {
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Compile(d, p)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Drop creates a DROP TABLE statement for the given tables. There must be
+// at least one table.
+func Drop(tables ...*TableElem) (stmt DropStmt) {
+	if len(tables) == 0 {
+		stmt.SetError("aspect: DROP TABLE requires at least one table")
+		return
+	}
+	for _, table := range tables {
+		if table == nil {
+			stmt.SetError("aspect: attempting to DROP a nil table")
+			return
+		}
 	}
-	return fmt.Sprintf(`DROP TABLE "%s"`, stmt.table.Name()), nil
+	stmt.tables = tables
+	return
 }
@@ -8,4 +8,16 @@ func TestDropStmt(t *testing.T) {
 
 	// If exists
 	expect.SQL(`DROP TABLE IF EXISTS "users"`, users.Drop().IfExists())
+
+	// Cascade and restrict
+	expect.SQL(`DROP TABLE "users" CASCADE`, users.Drop().Cascade())
+	expect.SQL(`DROP TABLE "users" RESTRICT`, users.Drop().Restrict())
+
+	// Multiple tables
+	expect.SQL(
+		`DROP TABLE IF EXISTS "users", "edges" CASCADE`,
+		Drop(users, edges).IfExists().Cascade(),
+	)
+	expect.Error(Drop())
+	expect.Error(Drop(users, nil))
 }
@@ -0,0 +1,174 @@
+package aspect
+
+import "fmt"
+
+// ErrorTranslator is an optional interface for dialects that can translate
+// the errors of their driver into the typed errors of this package, such as
+// UniqueViolation. Errors it does not recognize should be returned as is.
+type ErrorTranslator interface {
+	TranslateError(error) error
+}
+
+// ConstraintError holds what is known about a violated constraint. Any of
+// its names may be empty if the driver did not report them. The TableElem
+// is set if the table is one of the tables of the executed statement.
+type ConstraintError struct {
+	Constraint string
+	Table      string
+	Columns    []string
+	TableElem  *TableElem
+	Err        error // The original driver error
+}
+
+func (e *ConstraintError) describe(kind string) string {
+	msg := "aspect: " + kind
+	if e.Constraint != "" {
+		msg += fmt.Sprintf(" of constraint %s", e.Constraint)
+	}
+	if e.Table != "" {
+		msg += fmt.Sprintf(" on table %s", e.Table)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the original driver error
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// constraint is implemented by every error that embeds a ConstraintError
+func (e *ConstraintError) constraint() *ConstraintError {
+	return e
+}
+
+// UniqueViolation is returned when a statement would duplicate the values
+// of a unique constraint or primary key
+type UniqueViolation struct {
+	ConstraintError
+}
+
+func (e *UniqueViolation) Error() string {
+	return e.describe("unique violation")
+}
+
+// ForeignKeyViolation is returned when a statement would leave a foreign
+// key referencing a row that does not exist
+type ForeignKeyViolation struct {
+	ConstraintError
+}
+
+func (e *ForeignKeyViolation) Error() string {
+	return e.describe("foreign key violation")
+}
+
+// NotNullViolation is returned when a statement would set a NULL value in a
+// NOT NULL column
+type NotNullViolation struct {
+	ConstraintError
+}
+
+func (e *NotNullViolation) Error() string {
+	return e.describe("not null violation")
+}
+
+// CheckViolation is returned when a statement would fail a CHECK constraint
+type CheckViolation struct {
+	ConstraintError
+}
+
+func (e *CheckViolation) Error() string {
+	return e.describe("check violation")
+}
+
+// SerializationFailure is returned when a transaction could not be
+// serialized with concurrent transactions. It may succeed if retried.
+type SerializationFailure struct {
+	Err error // The original driver error
+}
+
+func (e *SerializationFailure) Error() string {
+	return "aspect: serialization failure: " + e.Err.Error()
+}
+
+// Unwrap returns the original driver error
+func (e *SerializationFailure) Unwrap() error {
+	return e.Err
+}
+
+// Deadlock is returned when a transaction was chosen as the victim of a
+// deadlock. It may succeed if retried.
+type Deadlock struct {
+	Err error // The original driver error
+}
+
+func (e *Deadlock) Error() string {
+	return "aspect: deadlock: " + e.Err.Error()
+}
+
+// Unwrap returns the original driver error
+func (e *Deadlock) Unwrap() error {
+	return e.Err
+}
+
+// translateError translates the given error with the dialect, if it is an
+// ErrorTranslator. The table of a constraint error is mapped back to the
+// matching table of the given statement, which may be nil.
+func translateError(d Dialect, stmt Executable, err error) error {
+	if err == nil {
+		return nil
+	}
+	translator, ok := d.(ErrorTranslator)
+	if !ok {
+		return err
+	}
+	translated := translator.TranslateError(err)
+	if violation, ok := translated.(interface {
+		constraint() *ConstraintError
+	}); ok {
+		c := violation.constraint()
+		if c.TableElem == nil {
+			c.TableElem = matchTable(stmtTables(stmt), c.Table)
+		}
+		if c.Table == "" && c.TableElem != nil {
+			c.Table = c.TableElem.Name()
+		}
+	}
+	return translated
+}
+
+// stmtTables returns the tables of the given statement, if they are known
+func stmtTables(stmt Executable) []*TableElem {
+	switch s := stmt.(type) {
+	case interface {
+		Tables() []*TableElem
+	}:
+		return s.Tables()
+	case interface {
+		Table() *TableElem
+	}:
+		if table := s.Table(); table != nil {
+			return []*TableElem{table}
+		}
+	}
+	return nil
+}
+
+// matchTable returns the table with the given name, or the only table if
+// the name is unknown
+func matchTable(tables []*TableElem, name string) *TableElem {
+	if name == "" {
+		if len(tables) == 1 {
+			return tables[0]
+		}
+		return nil
+	}
+	for _, table := range tables {
+		if table != nil && (table.Name() == name || qualifiedName(table) == name) {
+			return table
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package aspect
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errDuplicate = errors.New("duplicate")
+
+// translateDialect translates errDuplicate into a unique violation of the
+// named table
+type translateDialect struct {
+	defaultDialect
+	table string
+}
+
+func (d *translateDialect) TranslateError(err error) error {
+	if err != errDuplicate {
+		return err
+	}
+	return &UniqueViolation{ConstraintError{
+		Constraint: "users_name_key",
+		Table:      d.table,
+		Columns:    []string{"name"},
+		Err:        err,
+	}}
+}
+
+// failingExecer returns the given error from every call
+type failingExecer struct {
+	err error
+}
+
+func (f failingExecer) Exec(s string, args ...interface{}) (sql.Result, error) {
+	return nil, f.err
+}
+
+func (f failingExecer) Query(s string, args ...interface{}) (*sql.Rows, error) {
+	return nil, f.err
+}
+
+func TestTranslateError(t *testing.T) {
+	d := &translateDialect{table: "users"}
+	insert := users.Insert()
+
+	err := translateError(d, insert, errDuplicate)
+	var unique *UniqueViolation
+	require.True(t, errors.As(err, &unique))
+	assert.Equal(t, "users_name_key", unique.Constraint)
+	assert.Equal(t, "users", unique.Table)
+	assert.Equal(t, []string{"name"}, unique.Columns)
+	assert.Equal(t, users, unique.TableElem)
+	assert.True(t, errors.Is(err, errDuplicate))
+	assert.Equal(t,
+		"aspect: unique violation of constraint users_name_key on table users: duplicate",
+		err.Error(),
+	)
+
+	// Tables that are not part of the statement are not mapped
+	err = translateError(d, views.Delete(), errDuplicate)
+	require.True(t, errors.As(err, &unique))
+	assert.Nil(t, unique.TableElem)
+
+	// An unknown table name is mapped to the only table of the statement
+	err = translateError(&translateDialect{}, insert, errDuplicate)
+	require.True(t, errors.As(err, &unique))
+	assert.Equal(t, "users", unique.Table)
+	assert.Equal(t, users, unique.TableElem)
+
+	// But not if the statement has more than one table
+	stmt := Select(users.C["name"], views.C["url"])
+	err = translateError(&translateDialect{}, stmt, errDuplicate)
+	require.True(t, errors.As(err, &unique))
+	assert.Nil(t, unique.TableElem)
+
+	// Other errors and dialects are unchanged
+	other := fmt.Errorf("other")
+	assert.Equal(t, other, translateError(d, insert, other))
+	assert.Equal(t, errDuplicate, translateError(&defaultDialect{}, insert, errDuplicate))
+	assert.Nil(t, translateError(d, insert, nil))
+}
+
+func TestMiddleware_TranslateError(t *testing.T) {
+	var hooked error
+	m := middleware{}.withHook(func(event Event) {
+		hooked = event.Err
+	})
+	statement := Statement{
+		Stmt:    users.Insert(),
+		Dialect: &translateDialect{table: "users"},
+	}
+
+	// Errors are only translated if enabled
+	_, err := m.exec(failingExecer{errDuplicate}, statement)
+	assert.Equal(t, errDuplicate, err)
+	assert.Equal(t, errDuplicate, hooked)
+
+	m.translate = true
+	var unique *UniqueViolation
+	_, err = m.exec(failingExecer{errDuplicate}, statement)
+	assert.True(t, errors.As(err, &unique))
+	assert.True(t, errors.As(hooked, &unique))
+
+	_, _, err = m.query(failingExecer{errDuplicate}, statement)
+	assert.True(t, errors.As(err, &unique))
+}
+
+func TestTypedErrors(t *testing.T) {
+	driverErr := fmt.Errorf("driver")
+	errs := []error{
+		&ForeignKeyViolation{ConstraintError{Err: driverErr}},
+		&NotNullViolation{ConstraintError{Err: driverErr}},
+		&CheckViolation{ConstraintError{Err: driverErr}},
+		&SerializationFailure{Err: driverErr},
+		&Deadlock{Err: driverErr},
+	}
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, driverErr), "%T should unwrap", err)
+	}
+	assert.Equal(t,
+		"aspect: not null violation: driver",
+		(&NotNullViolation{ConstraintError{Err: driverErr}}).Error(),
+	)
+	assert.Equal(t, "aspect: deadlock: driver", (&Deadlock{Err: driverErr}).Error())
+}
@@ -15,6 +15,7 @@ type field struct {
 	column  string // SQL column name
 	table   string // SQL table name
 	options options
+	ptr     bool // the field is within a pointer to a struct
 }
 
 // Exists returns true if the field contains a valid recursive field index
@@ -52,29 +53,56 @@ func (f fields) HasColumn(column string) bool {
 
 // SelectFields returns the ordered list of fields from the given interface.
 func SelectFields(v interface{}) fields {
-	return recurse([]int{}, reflect.TypeOf(v).Elem())
+	return SelectFieldsFromElem(reflect.TypeOf(v).Elem())
 }
 
 // SelectFieldsFromElem returns the ordered list of fields from the given
-// reflect Type
+// reflect Type. The fields of nested structs and exported pointers to
+// structs are included.
 func SelectFieldsFromElem(elem reflect.Type) fields {
-	return recurse([]int{}, elem)
+	return recurse([]int{}, elem, false, map[reflect.Type]bool{})
 }
 
-func recurse(indexes []int, elem reflect.Type) (fields fields) {
+// isNested returns true if the fields of the given struct type should be
+// selected instead of the struct itself
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType &&
+		!reflect.PtrTo(t).Implements(scannerType) && !isConverted(t)
+}
+
+// destKind returns the kind of the given destination type. Converted types
+// and structs that are scanned whole, such as time.Time, are returned as
+// reflect.Invalid so that they are scanned as native types.
+func destKind(t reflect.Type) reflect.Kind {
+	if isConverted(t) || (t.Kind() == reflect.Struct && !isNested(t)) {
+		return reflect.Invalid
+	}
+	return t.Kind()
+}
+
+func recurse(indexes []int, elem reflect.Type, ptr bool, parents map[reflect.Type]bool) (fields fields) {
 	if elem.Kind() != reflect.Struct {
 		return nil
 	}
 
+	// Prevent infinite recursion of self-referencing pointers
+	parents[elem] = true
+	defer delete(parents, elem)
+
 	for i := 0; i < elem.NumField(); i += 1 {
 		f := elem.Field(i)
-		if f.Type.Kind() == reflect.Struct && !reflect.PtrTo(f.Type).Implements(scannerType) {
-			switch f.Type.String() { // TODO switch on the actual type
-			case "time.Time":
-			default:
-				fields = append(fields, recurse(append(indexes, i), f.Type)...)
+		if isNested(f.Type) {
+			fields = append(fields, recurse(append(indexes, i), f.Type, ptr, parents)...)
+			continue
+		}
+
+		// Exported pointers to structs are allocated when scanned
+		if f.Type.Kind() == reflect.Ptr && isNested(f.Type.Elem()) && f.Tag.Get(tagLabel) != "-" {
+			if f.PkgPath != "" || parents[f.Type.Elem()] {
 				continue
 			}
+			fields = append(fields, recurse(append(indexes, i), f.Type.Elem(), true, parents)...)
+			continue
 		}
 
 		// TODO ignore un-exported fields?
@@ -88,7 +116,7 @@ func recurse(indexes []int, elem reflect.Type) (fields fields) {
 		// perform a copy to allocate new memory
 		indexesCopy := make([]int, len(indexes))
 		copy(indexesCopy, indexes)
-		field := field{index: append(indexesCopy, i)}
+		field := field{index: append(indexesCopy, i), ptr: ptr}
 		tag, field.options = parseTag(f.Tag.Get(tagLabel))
 		if tag == "-" {
 			continue
@@ -107,16 +135,61 @@ func recurse(indexes []int, elem reflect.Type) (fields fields) {
 
 // AlignColumns will reorder the given fields array to match the columns.
 // Columns that do not match fields will be given empty field structs.
+// Columns may be qualified by their table, such as users.id, in which case
+// only unqualified fields or those of the same table will match, with the
+// latter preferred. Otherwise, if any field is qualified by its table,
+// fields that have not yet been matched are preferred, which allows columns
+// of the same name to be matched to fields in order. Without qualified
+// fields, every column of the same name matches the first such field.
 func AlignColumns(columns []string, fields []field) fields {
 	aligned := make([]field, len(columns))
-	// TODO aliases? tables? check if the columns first matches the fields?
+	used := make([]bool, len(fields))
+	var qualified bool
+	for _, field := range fields {
+		if field.table != "" {
+			qualified = true
+			break
+		}
+	}
 	for i, column := range columns {
-		for _, field := range fields {
-			if field.column == column {
-				aligned[i] = field
+		table, name := splitName(column)
+		match := -1
+		for j, field := range fields {
+			// Fields of other tables cannot match qualified columns
+			if field.column != name || (table != "" && field.table != "" && field.table != table) {
+				continue
+			}
+			if table != "" && field.table == table {
+				match = j
 				break
 			}
+			if match == -1 || (qualified && used[match] && !used[j]) {
+				match = j
+			}
+		}
+		if match != -1 {
+			aligned[i] = fields[match]
+			used[match] = true
 		}
 	}
 	return aligned
 }
+
+// fieldByIndex returns the field of the given struct value at the index.
+// Nil pointers to structs along the index will be allocated if alloc is
+// true, otherwise an invalid value is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
@@ -117,4 +117,67 @@ func TestAlignColumns(t *testing.T) {
 	assert.Equal(fields[1], aligned[0])
 	assert.Equal(fields[0], aligned[1])
 	assert.False(aligned[2].Exists())
+
+	// Without qualified fields, duplicate column names all match the
+	// first field of that name
+	fields = append(fields, field{index: []int{2}, column: "id"})
+	aligned = AlignColumns([]string{"id", "name", "id"}, fields)
+	require.Equal(t, 3, len(aligned))
+	assert.Equal(fields[1], aligned[0])
+	assert.Equal(fields[0], aligned[1])
+	assert.Equal(fields[1], aligned[2])
+}
+
+type node struct {
+	ID     int64 `db:"id"`
+	Parent *node
+}
+
+type address struct {
+	ID   int64  `db:"addresses.id"`
+	City string `db:"city"`
+}
+
+type userAddress struct {
+	ID      int64  `db:"users.id"`
+	Name    string `db:"name"`
+	Address *address
+	private *address
+}
+
+func TestFields_Pointers(t *testing.T) {
+	fields := SelectFields(&userAddress{})
+	require.Equal(t, 4, len(fields))
+	assert.Equal(t, []int{2, 0}, fields[2].index)
+	assert.Equal(t, "addresses", fields[2].table)
+	assert.True(t, fields[2].ptr)
+	assert.True(t, fields[3].ptr)
+	assert.False(t, fields[0].ptr)
+
+	// Self-referencing pointers are not followed
+	fields = SelectFields(&node{})
+	require.Equal(t, 1, len(fields))
+}
+
+func TestAlignColumns_Qualified(t *testing.T) {
+	fields := SelectFields(&userAddress{})
+
+	// Qualified columns prefer fields of the same table
+	aligned := AlignColumns(
+		[]string{"addresses.id", "users.id", "users.name", "addresses.city"},
+		fields,
+	)
+	assert.Equal(t, fields[2], aligned[0])
+	assert.Equal(t, fields[0], aligned[1])
+	assert.Equal(t, fields[1], aligned[2])
+	assert.Equal(t, fields[3], aligned[3])
+
+	// Unqualified columns of the same name are matched in order
+	aligned = AlignColumns([]string{"id", "name", "id"}, fields)
+	assert.Equal(t, fields[0], aligned[0])
+	assert.Equal(t, fields[2], aligned[2])
+
+	// Fields of other tables do not match qualified columns
+	aligned = AlignColumns([]string{"teams.id"}, fields)
+	assert.False(t, aligned[0].Exists())
 }
@@ -5,16 +5,18 @@ import (
 	"log"
 )
 
-type fkAction string
+// ForeignKeyAction is the referential action of an ON DELETE or ON UPDATE
+// clause.
+type ForeignKeyAction string
 
 // The following constants represent possible foreign key actions that can
 // be used in ON DELETE and ON UPDATE clauses.
 const (
-	NoAction   fkAction = "NO ACTION"
-	Restrict   fkAction = "RESTRICT"
-	Cascade    fkAction = "CASCADE"
-	SetNull    fkAction = "SET NULL"
-	SetDefault fkAction = "SET DEFAULT"
+	NoAction   ForeignKeyAction = "NO ACTION"
+	Restrict   ForeignKeyAction = "RESTRICT"
+	Cascade    ForeignKeyAction = "CASCADE"
+	SetNull    ForeignKeyAction = "SET NULL"
+	SetDefault ForeignKeyAction = "SET DEFAULT"
 )
 
 // ForeignKeyElem is an internal type representation. It implements the
@@ -25,8 +27,8 @@ type ForeignKeyElem struct {
 	typ      Type
 	table    *TableElem // the parent table of the key
 	refTable *TableElem // the table the key references
-	onDelete *fkAction
-	onUpdate *fkAction
+	onDelete *ForeignKeyAction
+	onUpdate *ForeignKeyAction
 }
 
 var _ Creatable = ForeignKeyElem{}
@@ -38,11 +40,16 @@ func (fk ForeignKeyElem) Create(d Dialect) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// Tables within a schema must be referenced by their qualified name
+	ref := fk.col.table.Name()
+	if fk.col.table.Schema() != "" {
+		ref = fk.col.table.Compile(d, Params())
+	}
 	compiled := fmt.Sprintf(
 		`"%s" %s REFERENCES %s("%s")`,
 		fk.name,
 		ct,
-		fk.col.table.Name(),
+		ref,
 		fk.col.Name(),
 	)
 	if fk.onDelete != nil {
@@ -102,18 +109,30 @@ func (fk ForeignKeyElem) Modify(t *TableElem) error {
 	return nil
 }
 
+// Actions returns the ON DELETE and ON UPDATE actions of the foreign key.
+// Actions that were not set will be empty.
+func (fk ForeignKeyElem) Actions() (onDelete, onUpdate string) {
+	if fk.onDelete != nil {
+		onDelete = string(*fk.onDelete)
+	}
+	if fk.onUpdate != nil {
+		onUpdate = string(*fk.onUpdate)
+	}
+	return
+}
+
 func (fk ForeignKeyElem) Name() string {
 	return fk.name
 }
 
 // OnDelete adds an ON DELETE clause to the foreign key
-func (fk ForeignKeyElem) OnDelete(b fkAction) ForeignKeyElem {
+func (fk ForeignKeyElem) OnDelete(b ForeignKeyAction) ForeignKeyElem {
 	fk.onDelete = &b
 	return fk
 }
 
 // OnUpdate add an ON UPDATE clause to the foreign key
-func (fk ForeignKeyElem) OnUpdate(b fkAction) ForeignKeyElem {
+func (fk ForeignKeyElem) OnUpdate(b ForeignKeyAction) ForeignKeyElem {
 	fk.onUpdate = &b
 	return fk
 }
@@ -163,6 +182,18 @@ type SelfForeignKeyElem struct {
 	ref string
 }
 
+// OnDelete adds an ON DELETE clause to the self-referencing foreign key
+func (fk SelfForeignKeyElem) OnDelete(b ForeignKeyAction) SelfForeignKeyElem {
+	fk.ForeignKeyElem = fk.ForeignKeyElem.OnDelete(b)
+	return fk
+}
+
+// OnUpdate adds an ON UPDATE clause to the self-referencing foreign key
+func (fk SelfForeignKeyElem) OnUpdate(b ForeignKeyAction) SelfForeignKeyElem {
+	fk.ForeignKeyElem = fk.ForeignKeyElem.OnUpdate(b)
+	return fk
+}
+
 func (fk SelfForeignKeyElem) Modify(t *TableElem) error {
 	if t == nil {
 		return fmt.Errorf("aspect: columns cannot modify a nil table")
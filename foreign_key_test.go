@@ -29,6 +29,16 @@ var messages = Table("messages",
 	SelfForeignKey("parent_id", "id", Integer{}),
 )
 
+var replies = Table("replies",
+	Column("id", Integer{NotNull: true, PrimaryKey: true}),
+	SelfForeignKey("parent_id", "id", Integer{}).OnDelete(SetNull).OnUpdate(Cascade),
+)
+
+var logEntries = Table("entries",
+	Schema("audit"),
+	ForeignKey("log_id", logs.C["id"], Integer{}),
+)
+
 func TestSelfForeignKey(t *testing.T) {
 	expect := NewTester(t, &defaultDialect{})
 	expect.SQL(
@@ -38,6 +48,13 @@ func TestSelfForeignKey(t *testing.T) {
 );`,
 		messages.Create(),
 	)
+	expect.SQL(
+		`CREATE TABLE "replies" (
+  "id" INTEGER PRIMARY KEY NOT NULL,
+  "parent_id" INTEGER REFERENCES replies("id") ON DELETE SET NULL ON UPDATE CASCADE
+);`,
+		replies.Create(),
+	)
 }
 
 func TestForeignKeyElement(t *testing.T) {
@@ -76,6 +93,12 @@ func TestForeignKey_Create(t *testing.T) {
 );`
 	expect.SQL(expected, childrenCascade.Create())
 
+	// Tables in a schema should be referenced by their qualified name
+	expected = `CREATE TABLE "audit"."entries" (
+  "log_id" INTEGER REFERENCES "audit"."logs"("id")
+);`
+	expect.SQL(expected, logEntries.Create())
+
 	// Test too many overrides
 	assert.Panics(t, func() {
 		Table("bad", ForeignKey("no", parents.C["id"], String{}, Integer{}))
@@ -0,0 +1,544 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aodin/aspect"
+	"github.com/aodin/aspect/postgis"
+	"github.com/aodin/aspect/postgres"
+)
+
+// ParseDDL parses the CREATE TABLE statements of the given SQL and returns
+// their tables registered with a MetaData. All other statements are
+// ignored. Column types are matched to aspect, postgres and postgis types,
+// with all others output as an aspect.RawType. Foreign keys that form a
+// cycle are reported with an aspect.UnresolvedForeignKeys error.
+func ParseDDL(r io.Reader) (*aspect.MetaData, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenize(string(b))
+	if err != nil {
+		return nil, err
+	}
+	ddl := &ddlReflector{tables: make(map[string]aspect.TableInfo)}
+	for _, stmt := range splitTokens(tokens, ";") {
+		if err := ddl.parse(stmt); err != nil {
+			return nil, err
+		}
+	}
+	if err := ddl.resolve(); err != nil {
+		return nil, err
+	}
+	return aspect.ReflectAllWith(ddl, nil)
+}
+
+// ddlReflector implements the aspect.Reflector interface for parsed DDL
+type ddlReflector struct {
+	names  []string
+	tables map[string]aspect.TableInfo
+
+	// Foreign keys whose referenced column was implied by the primary key
+	implied map[string][]int
+}
+
+var _ aspect.Reflector = &ddlReflector{}
+
+func (ddl *ddlReflector) TableNames(conn aspect.Connection) ([]string, error) {
+	return ddl.names, nil
+}
+
+func (ddl *ddlReflector) ReflectTable(conn aspect.Connection, name string) (aspect.TableInfo, error) {
+	info, ok := ddl.tables[name]
+	if !ok {
+		return info, fmt.Errorf("gen: no CREATE TABLE statement for %s", name)
+	}
+	return info, nil
+}
+
+// parse adds the table of the given statement if it is a CREATE TABLE
+func (ddl *ddlReflector) parse(stmt []string) error {
+	p := &parser{tokens: stmt}
+	if !p.keyword("CREATE") {
+		return nil
+	}
+	for p.keyword("TEMPORARY") || p.keyword("TEMP") || p.keyword("UNLOGGED") {
+	}
+	if !p.keyword("TABLE") {
+		return nil
+	}
+	if p.keyword("IF") && !(p.keyword("NOT") && p.keyword("EXISTS")) {
+		return fmt.Errorf("gen: malformed IF NOT EXISTS")
+	}
+
+	var info aspect.TableInfo
+	info.Name = unquote(p.next())
+	name := info.Name
+	if p.peek() == "." {
+		p.next()
+		info.Schema, info.Name = info.Name, unquote(p.next())
+		name = info.Schema + "." + info.Name
+	}
+	if p.next() != "(" {
+		// Tables created AS SELECT cannot be parsed
+		return nil
+	}
+	body, ok := p.group()
+	if !ok {
+		return fmt.Errorf("gen: unterminated CREATE TABLE statement for %s", name)
+	}
+
+	for _, definition := range splitTokens(body, ",") {
+		if err := ddl.definition(name, &info, definition); err != nil {
+			return err
+		}
+	}
+	if _, exists := ddl.tables[name]; !exists {
+		ddl.names = append(ddl.names, name)
+	}
+	ddl.tables[name] = info
+	return nil
+}
+
+// definition parses a single column or table constraint definition
+func (ddl *ddlReflector) definition(name string, info *aspect.TableInfo, tokens []string) error {
+	p := &parser{tokens: tokens}
+	if p.keyword("CONSTRAINT") {
+		p.next()
+	}
+
+	switch {
+	case p.keyword("PRIMARY"):
+		p.keyword("KEY")
+		info.PrimaryKey = p.names()
+		return nil
+	case p.keyword("UNIQUE"):
+		p.keyword("KEY")
+		info.Uniques = append(info.Uniques, p.names())
+		return nil
+	case p.keyword("FOREIGN"):
+		p.keyword("KEY")
+		columns := p.names()
+		if !p.keyword("REFERENCES") {
+			return fmt.Errorf("gen: FOREIGN KEY of %s is missing REFERENCES", name)
+		}
+		// Multi-column foreign keys are not supported
+		if len(columns) == 1 {
+			ddl.reference(name, info, columns[0], p)
+		}
+		return nil
+	case p.keyword("CHECK") || p.keyword("EXCLUDE") || p.keyword("KEY") || p.keyword("INDEX"):
+		return nil
+	}
+
+	// Column definition
+	column := unquote(p.next())
+	var typeTokens []string
+	for !p.done() && !isConstraint(p.peek()) {
+		typeTokens = append(typeTokens, p.next())
+	}
+	declared := joinTokens(typeTokens)
+
+	var notNull bool
+	var def interface{}
+	for !p.done() {
+		switch {
+		case p.keyword("NOT"):
+			p.keyword("NULL")
+			notNull = true
+		case p.keyword("NULL"):
+		case p.keyword("PRIMARY"):
+			p.keyword("KEY")
+			notNull = true
+			info.PrimaryKey = []string{column}
+		case p.keyword("UNIQUE"):
+			info.Uniques = append(info.Uniques, []string{column})
+		case p.keyword("DEFAULT"):
+			// A default of NULL is the same as no default
+			if p.keyword("NULL") {
+				def = nil
+				break
+			}
+			var expr []string
+			for !p.done() && !isConstraint(p.peek()) {
+				token := p.next()
+				// Keep the sign of a numeric literal with its digits
+				if (token == "-" || token == "+") && isNumber(p.peek()) &&
+					(len(expr) == 0 || expr[len(expr)-1] == "(") {
+					token += p.next()
+				}
+				expr = append(expr, token)
+			}
+			def = aspect.Raw(joinTokens(expr))
+		case p.keyword("REFERENCES"):
+			ddl.reference(name, info, column, p)
+		default:
+			// Skip other modifiers, such as COLLATE and AUTOINCREMENT
+			p.next()
+		}
+	}
+
+	info.Columns = append(info.Columns, aspect.ColumnInfo{
+		Name: column,
+		Type: ParseType(declared, notNull, def),
+	})
+	return nil
+}
+
+// reference adds a foreign key for the given column from a REFERENCES
+// clause, including its optional column list and actions
+func (ddl *ddlReflector) reference(name string, info *aspect.TableInfo, column string, p *parser) {
+	fk := aspect.ForeignKeyInfo{Column: column}
+	fk.RefTable = unquote(p.next())
+	if p.peek() == "." {
+		p.next()
+		fk.RefTable += "." + unquote(p.next())
+	}
+	if p.peek() == "(" {
+		if names := p.names(); len(names) > 0 {
+			fk.RefColumn = names[0]
+		}
+	}
+	for p.keyword("ON") {
+		var action string
+		if p.keyword("DELETE") {
+			action = "DELETE"
+		} else if p.keyword("UPDATE") {
+			action = "UPDATE"
+		}
+		var words []string
+		for !p.done() && isActionWord(p.peek()) {
+			words = append(words, strings.ToUpper(p.next()))
+		}
+		parsed := aspect.ParseAction(strings.Join(words, " "))
+		if action == "DELETE" {
+			fk.OnDelete = parsed
+		} else {
+			fk.OnUpdate = parsed
+		}
+	}
+	if fk.RefColumn == "" {
+		if ddl.implied == nil {
+			ddl.implied = make(map[string][]int)
+		}
+		ddl.implied[name] = append(ddl.implied[name], len(info.ForeignKeys))
+	}
+	info.ForeignKeys = append(info.ForeignKeys, fk)
+}
+
+// resolve sets the referenced column of foreign keys that implicitly
+// reference a primary key
+func (ddl *ddlReflector) resolve() error {
+	for name, indexes := range ddl.implied {
+		info := ddl.tables[name]
+		for _, i := range indexes {
+			ref, ok := ddl.tables[info.ForeignKeys[i].RefTable]
+			if !ok || len(ref.PrimaryKey) != 1 {
+				return fmt.Errorf(
+					"gen: cannot determine the column referenced by %s.%s",
+					name, info.ForeignKeys[i].Column,
+				)
+			}
+			info.ForeignKeys[i].RefColumn = ref.PrimaryKey[0]
+		}
+	}
+	return nil
+}
+
+// ParseType converts the declared SQL type of a column, such as
+// VARCHAR(32), to an aspect, postgres or postgis type. Unknown types, such
+// as the DATETIME of sqlite3, are returned as an aspect.RawType.
+func ParseType(declared string, notNull bool, def interface{}) aspect.Type {
+	upper := strings.ToUpper(strings.TrimSpace(declared))
+	base, args := upper, ""
+	if i := strings.Index(upper, "("); i != -1 && strings.HasSuffix(upper, ")") {
+		base = strings.TrimSpace(upper[:i])
+		args = upper[i+1 : len(upper)-1]
+	}
+	length, _ := strconv.Atoi(strings.TrimSpace(args))
+
+	switch base {
+	case "INTEGER", "INT", "INT4", "SMALLINT", "INT2":
+		return aspect.Integer{NotNull: notNull, Default: def}
+	case "BIGINT", "INT8":
+		return aspect.BigInt{NotNull: notNull, Default: def}
+	case "SERIAL", "SERIAL4":
+		return postgres.Serial{NotNull: notNull}
+	case "VARCHAR", "CHARACTER VARYING":
+		return aspect.String{Length: length, NotNull: notNull, Default: def}
+	case "TEXT":
+		return aspect.Text{NotNull: notNull, Default: def}
+	case "BOOLEAN", "BOOL":
+		return aspect.Boolean{NotNull: notNull, Default: def}
+	case "TIMESTAMP", "TIMESTAMP WITHOUT TIME ZONE":
+		return aspect.Timestamp{NotNull: notNull, Default: def}
+	case "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE":
+		return aspect.Timestamp{WithTimezone: true, NotNull: notNull, Default: def}
+	case "DATE":
+		return aspect.Date{NotNull: notNull, Default: def}
+	case "REAL", "FLOAT4":
+		return aspect.Real{NotNull: notNull, Default: def}
+	case "DOUBLE", "DOUBLE PRECISION", "FLOAT8":
+		return aspect.Double{NotNull: notNull, Default: def}
+	case "UUID":
+		return postgres.UUID{NotNull: notNull, Default: def}
+	case "JSON":
+		return postgres.JSON{NotNull: notNull, Default: def}
+	case "INET":
+		return postgres.Inet{NotNull: notNull, Default: def}
+	case "DATERANGE":
+		return postgres.DateRange{}
+	}
+	if g, ok := postgis.ParseGeometry(declared); ok {
+		return g
+	}
+	return aspect.RawType{Name: declared, NotNull: notNull, Default: def}
+}
+
+// convert replaces raw types that have a matching type, such as reflected
+// postgis geometries
+func convert(typ aspect.Type) aspect.Type {
+	if raw, ok := typ.(aspect.RawType); ok {
+		if g, ok := postgis.ParseGeometry(raw.Name); ok {
+			return g
+		}
+	}
+	return typ
+}
+
+// constraints are the keywords that end the type of a column definition
+var constraints = map[string]bool{
+	"CONSTRAINT":     true,
+	"NOT":            true,
+	"NULL":           true,
+	"PRIMARY":        true,
+	"UNIQUE":         true,
+	"DEFAULT":        true,
+	"REFERENCES":     true,
+	"CHECK":          true,
+	"COLLATE":        true,
+	"AUTOINCREMENT":  true,
+	"AUTO_INCREMENT": true,
+	"GENERATED":      true,
+}
+
+func isConstraint(token string) bool {
+	return constraints[strings.ToUpper(token)]
+}
+
+func isActionWord(token string) bool {
+	switch strings.ToUpper(token) {
+	case "CASCADE", "RESTRICT", "SET", "NULL", "DEFAULT", "NO", "ACTION":
+		return true
+	}
+	return false
+}
+
+// parser consumes the tokens of a single statement or definition
+type parser struct {
+	tokens []string
+	i      int
+}
+
+func (p *parser) done() bool {
+	return p.i >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.i++
+	return token
+}
+
+// keyword consumes the next token if it is the given case-insensitive
+// keyword
+func (p *parser) keyword(word string) bool {
+	if strings.ToUpper(p.peek()) == word {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// group returns the tokens until the parenthesis that closes a group whose
+// opening parenthesis was already consumed
+func (p *parser) group() ([]string, bool) {
+	var tokens []string
+	depth := 1
+	for !p.done() {
+		token := p.next()
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return tokens, true
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, false
+}
+
+// names returns the unquoted names of a parenthesized list, such as
+// ("a", "b")
+func (p *parser) names() []string {
+	if p.next() != "(" {
+		return nil
+	}
+	group, _ := p.group()
+	var names []string
+	for _, name := range splitTokens(group, ",") {
+		if len(name) > 0 {
+			names = append(names, unquote(name[0]))
+		}
+	}
+	return names
+}
+
+// tokenize splits SQL into words, quoted identifiers, string literals and
+// punctuation. Comments are removed.
+func tokenize(sql string) ([]string, error) {
+	var tokens []string
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			j := i + 2
+			for j+1 < len(runes) && !(runes[j] == '*' && runes[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(runes) {
+				return nil, fmt.Errorf("gen: unterminated comment")
+			}
+			i = j + 2
+		case r == '"' || r == '\'' || r == '`':
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					// Quotes are escaped by doubling
+					if j+1 < len(runes) && runes[j+1] == r {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("gen: unterminated quote %c", r)
+			}
+			tokens = append(tokens, string(runes[i:j+1]))
+			i = j + 1
+		case r == ':' && i+1 < len(runes) && runes[i+1] == ':':
+			tokens = append(tokens, "::")
+			i += 2
+		case isWordRune(r):
+			// Numbers may include a decimal point
+			number := unicode.IsDigit(r)
+			j := i
+			for j < len(runes) && (isWordRune(runes[j]) || (number && runes[j] == '.')) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isNumber(token string) bool {
+	return token != "" && unicode.IsDigit([]rune(token)[0])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
+// splitTokens splits tokens on the given separator outside of parentheses
+func splitTokens(tokens []string, sep string) [][]string {
+	var parts [][]string
+	var current []string
+	var depth int
+	for _, token := range tokens {
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case sep:
+			if depth == 0 {
+				if len(current) > 0 {
+					parts = append(parts, current)
+				}
+				current = nil
+				continue
+			}
+		}
+		current = append(current, token)
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// joinTokens joins tokens into SQL, such as NUMERIC(10, 2)
+func joinTokens(tokens []string) string {
+	var joined string
+	for i, token := range tokens {
+		if i > 0 {
+			prev := tokens[i-1]
+			if token != "(" && token != ")" && token != "," && token != "::" && prev != "(" && prev != "::" {
+				joined += " "
+			} else if token == "(" && !isWord(prev) {
+				joined += " "
+			}
+		}
+		joined += token
+	}
+	return joined
+}
+
+func isWord(token string) bool {
+	r := []rune(token)[0]
+	return unicode.IsLetter(r) || r == '_' || r == '"'
+}
+
+// unquote removes the quotes of an identifier
+func unquote(name string) string {
+	if len(name) > 1 {
+		switch q := name[0]; q {
+		case '"', '`':
+			if name[len(name)-1] == q {
+				return strings.Replace(
+					name[1:len(name)-1], string(q)+string(q), string(q), -1,
+				)
+			}
+		}
+	}
+	return name
+}
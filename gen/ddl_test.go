@@ -0,0 +1,105 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aodin/aspect"
+	"github.com/aodin/aspect/postgis"
+	"github.com/aodin/aspect/postgres"
+)
+
+const schema = `
+-- Teams and their players
+CREATE TABLE "teams" (
+  "id" SERIAL PRIMARY KEY,
+  "name" VARCHAR(32) NOT NULL UNIQUE,
+  "rating" NUMERIC(10, 2) DEFAULT 1.5,
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  "note" TEXT DEFAULT NULL,
+  "rank" INTEGER NOT NULL DEFAULT -1
+);
+
+CREATE INDEX "teams_name" ON "teams" ("name");
+
+/* Players may be captained by another player */
+CREATE TABLE IF NOT EXISTS players (
+  id INTEGER NOT NULL,
+  team_id INTEGER REFERENCES teams ON DELETE CASCADE,
+  captain_id INTEGER,
+  home geometry(Point, 4326),
+  CONSTRAINT players_pkey PRIMARY KEY (id),
+  FOREIGN KEY (captain_id) REFERENCES players(id) ON DELETE SET NULL
+);
+`
+
+func TestParseDDL(t *testing.T) {
+	meta, err := ParseDDL(strings.NewReader(schema))
+	require.Nil(t, err)
+
+	teams := meta.Table("teams")
+	require.NotNil(t, teams)
+	assert.Equal(t, []string{"id"}, []string(teams.PrimaryKey()))
+	assert.Equal(t, postgres.Serial{NotNull: true}, teams.C["id"].Type())
+	assert.Equal(t,
+		aspect.String{Length: 32, NotNull: true},
+		teams.C["name"].Type(),
+	)
+	assert.Equal(t,
+		aspect.RawType{Name: "NUMERIC(10, 2)", Default: aspect.Raw("1.5")},
+		teams.C["rating"].Type(),
+	)
+	assert.Equal(t,
+		aspect.Timestamp{WithTimezone: true, Default: aspect.Raw("now()")},
+		teams.C["created_at"].Type(),
+	)
+
+	// A default of NULL is no default and signs stay with their numbers
+	assert.Equal(t, aspect.Text{}, teams.C["note"].Type())
+	assert.Equal(t,
+		aspect.Integer{NotNull: true, Default: aspect.Raw("-1")},
+		teams.C["rank"].Type(),
+	)
+	assert.Equal(t,
+		`CREATE TABLE "teams" (
+  "id" SERIAL NOT NULL,
+  "name" VARCHAR(32) NOT NULL,
+  "rating" NUMERIC(10, 2) DEFAULT (1.5),
+  "created_at" TIMESTAMP WITH TIME ZONE DEFAULT (now()),
+  "note" TEXT,
+  "rank" INTEGER NOT NULL DEFAULT (-1),
+  PRIMARY KEY ("id"),
+  UNIQUE ("name")
+);`,
+		aspect.Debug(teams.Create(), &postgres.PostGres{}),
+	)
+
+	players := meta.Table("players")
+	require.NotNil(t, players)
+	assert.Equal(t, []string{"id"}, []string(players.PrimaryKey()))
+	assert.Equal(t,
+		postgis.Geometry{Geom: postgis.Point{}, SRID: 4326},
+		players.C["home"].Type(),
+	)
+	fks := players.ForeignKeys()
+	require.Equal(t, 2, len(fks))
+	assert.Equal(t, teams, fks[0].ReferencesTable())
+	onDelete, _ := fks[0].Actions()
+	assert.Equal(t, "CASCADE", onDelete)
+	assert.Equal(t, players, fks[1].ReferencesTable())
+
+	// Implied references require a single column primary key
+	_, err = ParseDDL(strings.NewReader(
+		`CREATE TABLE a (id INTEGER); CREATE TABLE b (a_id INTEGER REFERENCES a);`,
+	))
+	assert.NotNil(t, err)
+
+	// Unterminated comments and quotes are errors
+	_, err = ParseDDL(strings.NewReader(`CREATE TABLE "a (id INTEGER);`))
+	assert.NotNil(t, err)
+	_, err = ParseDDL(strings.NewReader(`/* CREATE TABLE a (id INTEGER);`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,395 @@
+// Package gen generates Go source code from aspect tables, such as those
+// reflected from a live database.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aodin/aspect"
+	"github.com/aodin/aspect/postgis"
+	"github.com/aodin/aspect/postgres"
+)
+
+const aspectPath = "github.com/aodin/aspect"
+
+// actionNames are the names of the foreign key action constants
+var actionNames = map[string]string{
+	"NO ACTION":   "NoAction",
+	"RESTRICT":    "Restrict",
+	"CASCADE":     "Cascade",
+	"SET NULL":    "SetNull",
+	"SET DEFAULT": "SetDefault",
+}
+
+// timeTypes are the package qualified names of dialect types that scan
+// into a time.Time. They are matched by name so that generating code does
+// not require the cgo driver of the sqlite3 package.
+var timeTypes = map[string]bool{
+	"sqlite3.Datetime": true,
+}
+
+// rawTimes are the names of raw types that scan into a time.Time
+var rawTimes = map[string]bool{
+	"DATETIME": true,
+}
+
+// generator accumulates source code and the imports it requires
+type generator struct {
+	buffer  bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buffer, format, args...)
+}
+
+// qualify returns the package qualified name of the given type and records
+// its import
+func (g *generator) qualify(t reflect.Type) string {
+	g.imports[t.PkgPath()] = true
+	return fmt.Sprintf("%s.%s", path.Base(t.PkgPath()), t.Name())
+}
+
+// Tables writes the formatted Go source of a file in the given package that
+// declares each of the given tables as a package level variable.
+func Tables(w io.Writer, pkg string, tables ...*aspect.TableElem) error {
+	g := &generator{imports: map[string]bool{aspectPath: true}}
+
+	names := make(map[*aspect.TableElem]string)
+	for _, table := range tables {
+		names[table] = VarName(table)
+	}
+	for _, table := range tables {
+		if err := g.table(table, names); err != nil {
+			return err
+		}
+	}
+	return g.write(w, pkg)
+}
+
+// Generate writes the formatted Go source of a file in the given package
+// that declares each of the given tables as a package level variable along
+// with a struct for its rows. Struct fields of nullable columns are
+// pointers and those of columns with defaults are tagged omitempty.
+func Generate(w io.Writer, pkg string, tables ...*aspect.TableElem) error {
+	g := &generator{imports: map[string]bool{aspectPath: true}}
+
+	names := make(map[*aspect.TableElem]string)
+	for _, table := range tables {
+		names[table] = VarName(table)
+	}
+	for _, table := range tables {
+		if err := g.table(table, names); err != nil {
+			return err
+		}
+		g.structure(table, StructName(table))
+	}
+	return g.write(w, pkg)
+}
+
+// write formats the generated source with its package clause and imports
+func (g *generator) write(w io.Writer, pkg string) error {
+	var imports []string
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "package %s\n\nimport (\n", pkg)
+	for _, path := range imports {
+		fmt.Fprintf(&src, "\t%q\n", path)
+	}
+	fmt.Fprintf(&src, ")\n\n")
+	src.Write(g.buffer.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("gen: failed to format generated source: %s", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+func (g *generator) table(table *aspect.TableElem, names map[*aspect.TableElem]string) error {
+	fks := make(map[string]aspect.ForeignKeyElem)
+	for _, fk := range table.ForeignKeys() {
+		fks[fk.Name()] = fk
+	}
+
+	g.printf("var %s = aspect.Table(%q,\n", names[table], table.Name())
+	if table.Schema() != "" {
+		g.printf("aspect.Schema(%q),\n", table.Schema())
+	}
+	for _, column := range table.Columns() {
+		typ, err := g.value(reflect.ValueOf(convert(column.Type())))
+		if err != nil {
+			return err
+		}
+		fk, ok := fks[column.Name()]
+		if !ok {
+			g.printf("aspect.Column(%q, %s),\n", column.Name(), typ)
+			continue
+		}
+
+		ref := fk.ReferencesTable()
+		if ref == table {
+			g.printf("aspect.SelfForeignKey(%q, %q, %s)", column.Name(), fk.ForeignName(), typ)
+		} else {
+			refName, ok := names[ref]
+			if !ok {
+				return fmt.Errorf(
+					"gen: the table %s references the table %s, which was not given",
+					table.Name(), ref.Name(),
+				)
+			}
+			g.printf("aspect.ForeignKey(%q, %s.C[%q], %s)", column.Name(), refName, fk.ForeignName(), typ)
+		}
+		onDelete, onUpdate := fk.Actions()
+		if onDelete != "" {
+			g.printf(".OnDelete(aspect.%s)", actionNames[onDelete])
+		}
+		if onUpdate != "" {
+			g.printf(".OnUpdate(aspect.%s)", actionNames[onUpdate])
+		}
+		g.printf(",\n")
+	}
+	// Constraints declared by column types are not repeated
+	if pk := table.PrimaryKey(); len(pk) > 0 && !(len(pk) == 1 && table.C[pk[0]].Type().IsPrimaryKey()) {
+		g.printf("aspect.PrimaryKey(%s),\n", quoteAll(pk))
+	}
+	for _, unique := range table.UniqueConstraints() {
+		if len(unique) == 1 && table.C[unique[0]].Type().IsUnique() {
+			continue
+		}
+		g.printf("aspect.Unique(%s),\n", quoteAll(unique))
+	}
+	g.printf(")\n\n")
+	return nil
+}
+
+// structure outputs a struct with a field for each column of the table
+func (g *generator) structure(table *aspect.TableElem, name string) {
+	pk := table.PrimaryKey()
+	g.printf("type %s struct {\n", name)
+	for _, column := range table.Columns() {
+		typ := convert(column.Type())
+		inPK := len(pk) == 1 && pk[0] == column.Name()
+		goType := g.goType(typ)
+		if !inPK && !isNotNull(typ) && !strings.HasPrefix(goType, "[]") {
+			goType = "*" + goType
+		}
+		tag := column.Name()
+		if hasDefault(typ) {
+			tag += ",omitempty"
+		}
+		g.printf("%s %s `db:\"%s\"`\n", CamelCase(column.Name()), goType, tag)
+	}
+	g.printf("}\n\n")
+}
+
+// goType returns the Go type that will be scanned from a column of the
+// given type and records any import it requires
+func (g *generator) goType(typ aspect.Type) string {
+	if isTime(typ) {
+		g.imports["time"] = true
+		return "time.Time"
+	}
+	switch typ.(type) {
+	case aspect.Integer, aspect.BigInt, postgres.Serial:
+		return "int64"
+	case aspect.String, aspect.Text, postgres.UUID, postgres.Inet:
+		return "string"
+	case aspect.Boolean:
+		return "bool"
+	case aspect.Real:
+		return "float32"
+	case aspect.Double:
+		return "float64"
+	case postgres.JSON:
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	case postgis.Geometry:
+		return "[]byte"
+	}
+	return "string"
+}
+
+// isTime returns true if the given type scans into a time.Time
+func isTime(typ aspect.Type) bool {
+	switch t := typ.(type) {
+	case aspect.Timestamp, aspect.Date:
+		return true
+	case aspect.RawType:
+		return rawTimes[strings.ToUpper(t.Name)]
+	}
+	name := reflect.TypeOf(typ)
+	return timeTypes[path.Base(name.PkgPath())+"."+name.Name()]
+}
+
+// isNotNull returns true if the given type cannot be NULL
+func isNotNull(typ aspect.Type) bool {
+	if typ.IsPrimaryKey() {
+		return true
+	}
+	if _, ok := typ.(postgres.Serial); ok {
+		return true
+	}
+	return boolField(typ, "NotNull")
+}
+
+// hasDefault returns true if the database will provide a value for the
+// given type when one is omitted
+func hasDefault(typ aspect.Type) bool {
+	if _, ok := typ.(postgres.Serial); ok {
+		return true
+	}
+	if boolField(typ, "Autoincrement") {
+		return true
+	}
+	v := reflect.ValueOf(typ)
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	def := v.FieldByName("Default")
+	return def.IsValid() && def.Kind() == reflect.Interface && !def.IsNil()
+}
+
+// boolField returns the value of the boolean field with the given name, if
+// the type has one
+func boolField(typ aspect.Type, name string) bool {
+	v := reflect.ValueOf(typ)
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	field := v.FieldByName(name)
+	return field.IsValid() && field.Kind() == reflect.Bool && field.Bool()
+}
+
+// value returns the Go expression of the given value, which is usually an
+// aspect type. Only the non-zero fields of structs are output.
+func (g *generator) value(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return "nil", nil
+	}
+
+	switch clause := v.Interface().(type) {
+	case aspect.RawClause:
+		return fmt.Sprintf("aspect.Raw(%q)", clause.SQL), nil
+	case aspect.LiteralClause:
+		return fmt.Sprintf("aspect.Literal(%#v)", clause.Value), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		return g.value(v.Elem())
+	case reflect.Struct:
+		var fields []string
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" || isZero(v.Field(i)) {
+				continue
+			}
+			value, err := g.value(v.Field(i))
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, fmt.Sprintf("%s: %s", field.Name, value))
+		}
+		return fmt.Sprintf(
+			"%s{%s}", g.qualify(v.Type()), strings.Join(fields, ", "),
+		), nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.String:
+		return fmt.Sprintf("%#v", v.Interface()), nil
+	}
+	return "", fmt.Errorf("gen: unable to generate a value of type %s", v.Type())
+}
+
+// isZero returns true if the given value is the zero value of its type
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// VarName returns an exported Go identifier for the given table, such as
+// AuditLogs for the table audit.logs
+func VarName(table *aspect.TableElem) string {
+	name := table.Name()
+	if table.Schema() != "" {
+		name = table.Schema() + "_" + name
+	}
+	return CamelCase(name)
+}
+
+// StructName returns an exported Go identifier for the rows of the given
+// table, such as AuditLog for the table audit.logs. The name will not
+// collide with that returned by VarName.
+func StructName(table *aspect.TableElem) string {
+	name := VarName(table)
+	singular := name
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		singular = strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		singular = strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "ss"):
+	case strings.HasSuffix(name, "s"):
+		singular = strings.TrimSuffix(name, "s")
+	}
+	if singular == name {
+		singular += "Row"
+	}
+	return singular
+}
+
+// CamelCase converts the given SQL name to an exported Go identifier, such
+// as UserAccounts for user_accounts. Common initialisms, such as ID, are
+// capitalized.
+func CamelCase(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var camel string
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			camel += upper
+			continue
+		}
+		camel += strings.ToUpper(word[:1]) + word[1:]
+	}
+	if camel == "" || unicode.IsDigit(rune(camel[0])) {
+		camel = "T" + camel
+	}
+	return camel
+}
+
+// initialisms are capitalized by CamelCase
+var initialisms = map[string]bool{
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"SQL":  true,
+	"URL":  true,
+	"UUID": true,
+}
@@ -0,0 +1,115 @@
+package gen
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aodin/aspect"
+	"github.com/aodin/aspect/postgres"
+	"github.com/aodin/aspect/sqlite3"
+)
+
+var teams = aspect.Table("teams",
+	aspect.Column("id", postgres.Serial{NotNull: true}),
+	aspect.Column("name", aspect.String{Length: 32, NotNull: true}),
+	aspect.Column("created_at", aspect.Timestamp{Default: aspect.Raw("now()")}),
+	aspect.PrimaryKey("id"),
+	aspect.Unique("name"),
+)
+
+var players = aspect.Table("players",
+	aspect.Schema("league"),
+	aspect.ForeignKey(
+		"team_id", teams.C["id"], aspect.Integer{},
+	).OnDelete(aspect.Cascade),
+	aspect.Column("id", aspect.Integer{NotNull: true}),
+	aspect.SelfForeignKey("captain_id", "id", aspect.Integer{}).OnDelete(aspect.SetNull),
+	aspect.Column("position", aspect.Text{Default: "forward"}),
+)
+
+func TestTables(t *testing.T) {
+	var buffer bytes.Buffer
+	require.Nil(t, Tables(&buffer, "db", teams, players))
+	assert.Equal(t, `package db
+
+import (
+	"github.com/aodin/aspect"
+	"github.com/aodin/aspect/postgres"
+)
+
+var Teams = aspect.Table("teams",
+	aspect.Column("id", postgres.Serial{NotNull: true}),
+	aspect.Column("name", aspect.String{Length: 32, NotNull: true}),
+	aspect.Column("created_at", aspect.Timestamp{Default: aspect.Raw("now()")}),
+	aspect.PrimaryKey("id"),
+	aspect.Unique("name"),
+)
+
+var LeaguePlayers = aspect.Table("players",
+	aspect.Schema("league"),
+	aspect.ForeignKey("team_id", Teams.C["id"], aspect.Integer{}).OnDelete(aspect.Cascade),
+	aspect.Column("id", aspect.Integer{NotNull: true}),
+	aspect.SelfForeignKey("captain_id", "id", aspect.Integer{}).OnDelete(aspect.SetNull),
+	aspect.Column("position", aspect.Text{Default: "forward"}),
+)
+`, buffer.String())
+
+	// Referenced tables must also be given
+	assert.NotNil(t, Tables(&buffer, "db", players))
+}
+
+func TestCamelCase(t *testing.T) {
+	assert.Equal(t, "UserAccounts", CamelCase("user_accounts"))
+	assert.Equal(t, "UserID", CamelCase("user_id"))
+	assert.Equal(t, "T2015Logs", CamelCase("2015_logs"))
+	assert.Equal(t, "AuditLogs", VarName(aspect.Table("logs", aspect.Schema("audit"))))
+}
+
+func TestGenerate(t *testing.T) {
+	var buffer bytes.Buffer
+	require.Nil(t, Generate(&buffer, "db", teams))
+	assert.Equal(t, `package db
+
+import (
+	"github.com/aodin/aspect"
+	"github.com/aodin/aspect/postgres"
+	"time"
+)
+
+var Teams = aspect.Table("teams",
+	aspect.Column("id", postgres.Serial{NotNull: true}),
+	aspect.Column("name", aspect.String{Length: 32, NotNull: true}),
+	aspect.Column("created_at", aspect.Timestamp{Default: aspect.Raw("now()")}),
+	aspect.PrimaryKey("id"),
+	aspect.Unique("name"),
+)
+
+type Team struct {
+	ID        int64      `+"`"+`db:"id,omitempty"`+"`"+`
+	Name      string     `+"`"+`db:"name"`+"`"+`
+	CreatedAt *time.Time `+"`"+`db:"created_at,omitempty"`+"`"+`
+}
+`, buffer.String())
+}
+
+func TestStructName(t *testing.T) {
+	assert.Equal(t, "Team", StructName(teams))
+	assert.Equal(t, "LeaguePlayer", StructName(players))
+	assert.Equal(t, "Category", StructName(aspect.Table("categories")))
+	assert.Equal(t, "Address", StructName(aspect.Table("addresses")))
+	assert.Equal(t, "StaffRow", StructName(aspect.Table("staff")))
+}
+
+func TestIsTime(t *testing.T) {
+	assert.True(t, isTime(aspect.Timestamp{}))
+	assert.True(t, isTime(aspect.Date{}))
+	assert.True(t, isTime(sqlite3.Datetime{}))
+	assert.True(t, isTime(aspect.RawType{Name: "datetime"}))
+	assert.True(t, isTime(ParseType("DATETIME", true, nil)))
+	assert.False(t, isTime(aspect.RawType{Name: "INTERVAL"}))
+	assert.False(t, isTime(aspect.String{}))
+	assert.False(t, isTime(postgres.UUID{}))
+}
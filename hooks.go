@@ -0,0 +1,177 @@
+package aspect
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// Statement is a compiled statement that is about to be executed by a
+// connection. Interceptors may rewrite its SQL and arguments.
+type Statement struct {
+	Stmt          Executable // The statement before compilation
+	SQL           string
+	Args          []interface{}
+	Dialect       Dialect
+	Query         bool // True if the statement was given to Query
+	InTransaction bool
+}
+
+// Event describes a statement executed by a connection
+type Event struct {
+	Statement
+	Start        time.Time
+	Duration     time.Duration
+	RowsAffected int64 // -1 for queries or if unknown
+	Err          error
+}
+
+// Hook is called after every statement executed by a connection, including
+// those vetoed by an interceptor.
+type Hook func(Event)
+
+// Interceptor is called before every statement is executed by a connection.
+// It may rewrite the SQL and arguments of the statement, or veto it by
+// returning an error, which will be returned by the connection. Interceptors
+// are called in the order they were added.
+type Interceptor func(*Statement) error
+
+// middleware holds the hooks and interceptors of a connection, and whether
+// its errors are translated by the dialect
+type middleware struct {
+	hooks        []Hook
+	interceptors []Interceptor
+	translate    bool
+}
+
+// translateError translates the error of the given statement if
+// translation is enabled
+func (m middleware) translateError(statement Statement, err error) error {
+	if !m.translate {
+		return err
+	}
+	return translateError(statement.Dialect, statement.Stmt, err)
+}
+
+// withHook returns a copy of the middleware with the given hook added
+func (m middleware) withHook(hook Hook) middleware {
+	m.hooks = append(m.hooks[:len(m.hooks):len(m.hooks)], hook)
+	return m
+}
+
+// withInterceptor returns a copy of the middleware with the given
+// interceptor added
+func (m middleware) withInterceptor(interceptor Interceptor) middleware {
+	m.interceptors = append(
+		m.interceptors[:len(m.interceptors):len(m.interceptors)], interceptor,
+	)
+	return m
+}
+
+// execer is implemented by both sql.DB and sql.Tx
+type execer interface {
+	Exec(string, ...interface{}) (sql.Result, error)
+	Query(string, ...interface{}) (*sql.Rows, error)
+}
+
+// intercept calls the interceptors with the given statement, stopping at
+// the first error
+func (m middleware) intercept(statement *Statement) error {
+	for _, interceptor := range m.interceptors {
+		if err := interceptor(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify calls the hooks with the event of the given statement
+func (m middleware) notify(statement Statement, start time.Time, rows int64, err error) {
+	if len(m.hooks) == 0 {
+		return
+	}
+	event := Event{
+		Statement:    statement,
+		Start:        start,
+		Duration:     time.Since(start),
+		RowsAffected: rows,
+		Err:          err,
+	}
+	for _, hook := range m.hooks {
+		hook(event)
+	}
+}
+
+// exec executes the statement with the given connection. If enabled,
+// errors are translated by the dialect of the statement before the hooks
+// are called.
+func (m middleware) exec(conn execer, statement Statement) (sql.Result, error) {
+	start := time.Now()
+	if err := m.intercept(&statement); err != nil {
+		m.notify(statement, start, -1, err)
+		return nil, err
+	}
+	result, err := conn.Exec(statement.SQL, statement.Args...)
+	err = m.translateError(statement, err)
+	rows := int64(-1)
+	if err == nil {
+		if affected, rowsErr := result.RowsAffected(); rowsErr == nil {
+			rows = affected
+		}
+	}
+	m.notify(statement, start, rows, err)
+	return result, err
+}
+
+// query queries the statement with the given connection. It also returns
+// the SQL that was queried, which may have been rewritten by interceptors.
+func (m middleware) query(conn execer, statement Statement) (*sql.Rows, string, error) {
+	statement.Query = true
+	start := time.Now()
+	if err := m.intercept(&statement); err != nil {
+		m.notify(statement, start, -1, err)
+		return nil, statement.SQL, err
+	}
+	rows, err := conn.Query(statement.SQL, statement.Args...)
+	err = m.translateError(statement, err)
+	m.notify(statement, start, -1, err)
+	return rows, statement.SQL, err
+}
+
+// Logger returns a hook that logs every statement, its arguments, duration
+// and any error with the given logger. If the logger is nil, the standard
+// logger is used.
+func Logger(logger *log.Logger) Hook {
+	printf := log.Printf
+	if logger != nil {
+		printf = logger.Printf
+	}
+	return func(event Event) {
+		if event.Err != nil {
+			printf(
+				"aspect: %s %v (%s): %s",
+				event.SQL, event.Args, event.Duration, event.Err,
+			)
+			return
+		}
+		printf("aspect: %s %v (%s)", event.SQL, event.Args, event.Duration)
+	}
+}
+
+// SlowQueryLogger returns a hook that logs statements that took at least
+// the given threshold to execute. If the logger is nil, the standard
+// logger is used.
+func SlowQueryLogger(logger *log.Logger, threshold time.Duration) Hook {
+	printf := log.Printf
+	if logger != nil {
+		printf = logger.Printf
+	}
+	return func(event Event) {
+		if event.Duration >= threshold {
+			printf(
+				"aspect: slow statement (%s): %s %v",
+				event.Duration, event.SQL, event.Args,
+			)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package aspect
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockExecer struct {
+	executed []string
+}
+
+func (m *mockExecer) Exec(s string, args ...interface{}) (sql.Result, error) {
+	m.executed = append(m.executed, s)
+	return driverResult(3), nil
+}
+
+func (m *mockExecer) Query(s string, args ...interface{}) (*sql.Rows, error) {
+	m.executed = append(m.executed, s)
+	return nil, fmt.Errorf("no rows")
+}
+
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (r driverResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestMiddleware(t *testing.T) {
+	var events []Event
+	m := middleware{}.withHook(func(event Event) {
+		events = append(events, event)
+	})
+
+	// Copies do not share hooks
+	other := m.withHook(func(Event) {})
+	assert.Equal(t, 1, len(m.hooks))
+	assert.Equal(t, 2, len(other.hooks))
+
+	conn := &mockExecer{}
+	_, err := m.exec(conn, Statement{SQL: "DELETE", Args: []interface{}{1}})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "DELETE", events[0].SQL)
+	assert.Equal(t, []interface{}{1}, events[0].Args)
+	assert.EqualValues(t, 3, events[0].RowsAffected)
+	assert.False(t, events[0].Query)
+	assert.Nil(t, events[0].Err)
+
+	_, _, err = m.query(conn, Statement{SQL: "SELECT"})
+	require.NotNil(t, err)
+	require.Equal(t, 2, len(events))
+	assert.True(t, events[1].Query)
+	assert.EqualValues(t, -1, events[1].RowsAffected)
+	assert.Equal(t, err, events[1].Err)
+
+	// Interceptors may rewrite or veto statements
+	m = m.withInterceptor(func(statement *Statement) error {
+		statement.SQL = "/* app */ " + statement.SQL
+		return nil
+	}).withInterceptor(func(statement *Statement) error {
+		if strings.HasSuffix(statement.SQL, "DROP") {
+			return fmt.Errorf("vetoed")
+		}
+		return nil
+	})
+	_, err = m.exec(conn, Statement{SQL: "DROP"})
+	assert.Equal(t, "vetoed", err.Error())
+	assert.Equal(t, "/* app */ DROP", events[2].SQL)
+
+	_, s, _ := m.query(conn, Statement{SQL: "SELECT"})
+	assert.Equal(t, "/* app */ SELECT", s)
+	assert.Equal(t, []string{"DELETE", "SELECT", "/* app */ SELECT"}, conn.executed)
+}
+
+func TestLoggers(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	event := Event{
+		Statement: Statement{SQL: "SELECT 1", Args: []interface{}{2}},
+		Duration:  time.Millisecond,
+	}
+
+	Logger(logger)(event)
+	assert.Equal(t, "aspect: SELECT 1 [2] (1ms)\n", buf.String())
+
+	buf.Reset()
+	event.Err = fmt.Errorf("failed")
+	Logger(logger)(event)
+	assert.Equal(t, "aspect: SELECT 1 [2] (1ms): failed\n", buf.String())
+
+	buf.Reset()
+	SlowQueryLogger(logger, time.Second)(event)
+	assert.Equal(t, "", buf.String())
+
+	event.Duration = 2 * time.Second
+	SlowQueryLogger(logger, time.Second)(event)
+	assert.Equal(t, "aspect: slow statement (2s): SELECT 1 [2]\n", buf.String())
+}
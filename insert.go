@@ -17,10 +17,11 @@ var (
 // InsertStmt is the internal representation of an INSERT statement.
 type InsertStmt struct {
 	Stmt
-	table   *TableElem
-	columns []ColumnElem // TODO custom type for setter / getter operations
-	args    []interface{}
-	fields  fields
+	table        *TableElem
+	columns      []ColumnElem // TODO custom type for setter / getter operations
+	args         []interface{}
+	fields       fields
+	omitDefaults bool
 }
 
 // String outputs the parameter-less INSERT statement in a neutral dialect.
@@ -34,6 +35,14 @@ func (stmt InsertStmt) Table() *TableElem {
 	return stmt.table
 }
 
+// OmitDefaults will remove columns that have a DEFAULT when the matching
+// struct field has an empty value, allowing the database to set the value.
+// It must be called before Values.
+func (stmt InsertStmt) OmitDefaults() InsertStmt {
+	stmt.omitDefaults = true
+	return stmt
+}
+
 func (stmt *InsertStmt) AppendColumn(c ColumnElem) {
 	stmt.columns = append(stmt.columns, c)
 }
@@ -103,8 +112,8 @@ func (stmt InsertStmt) Compile(d Dialect, params *Parameters) (string, error) {
 
 	// TODO Bulk insert syntax is dialect specific
 	return fmt.Sprintf(
-		`INSERT INTO "%s" (%s) VALUES %s`,
-		stmt.table.Name(),
+		`INSERT INTO %s (%s) VALUES %s`,
+		stmt.table.Compile(d, params),
 		strings.Join(columns, ", "),
 		strings.Join(parameters, ", "),
 	), nil
@@ -146,6 +155,16 @@ func removeColumn(columns []ColumnElem, name string) []ColumnElem {
 	return columns
 }
 
+// omitsDefault returns true if the statement omits default values and the
+// column with the given name has a default.
+func (stmt InsertStmt) omitsDefault(name string) bool {
+	if !stmt.omitDefaults {
+		return false
+	}
+	column, exists := stmt.table.C[name]
+	return exists && column.HasDefault()
+}
+
 // A field marked omitempty can cause the removal of a column, only
 // to have another value not have an empty value for that field
 func (stmt *InsertStmt) trimFields(elem reflect.Value) {
@@ -156,12 +175,9 @@ func (stmt *InsertStmt) trimFields(elem reflect.Value) {
 		if !field.Exists() {
 			continue
 		}
-		if field.HasOption(OmitEmpty) {
-			var fieldElem reflect.Value = elem
-			for _, index := range field.index {
-				fieldElem = fieldElem.Field(index)
-			}
-			if isEmptyValue(fieldElem) {
+		if field.HasOption(OmitEmpty) || stmt.omitsDefault(field.column) {
+			fieldElem := fieldByIndex(elem, field.index, false)
+			if !fieldElem.IsValid() || isEmptyValue(fieldElem) {
 				// Remove the column
 				stmt.columns = removeColumn(stmt.columns, field.column)
 				continue
@@ -181,9 +197,11 @@ func (stmt *InsertStmt) argsFromValues(values Values) {
 
 func (stmt *InsertStmt) argsFromElem(elem reflect.Value) {
 	for _, field := range stmt.fields {
-		var fieldElem reflect.Value = elem
-		for _, index := range field.index {
-			fieldElem = fieldElem.Field(index)
+		// Fields within nil pointers to structs are inserted as NULL
+		fieldElem := fieldByIndex(elem, field.index, false)
+		if !fieldElem.IsValid() {
+			stmt.args = append(stmt.args, nil)
+			continue
 		}
 		stmt.args = append(stmt.args, fieldElem.Interface())
 	}
@@ -211,16 +229,15 @@ func (stmt InsertStmt) Values(arg interface{}) InsertStmt {
 
 	switch elem.Kind() {
 	case reflect.Struct:
-		// Inspect the fields of the given struct
-		unaligned := SelectFieldsFromElem(elem.Type())
-
 		// TODO function to return names of columns
 		columns := make([]string, len(stmt.columns))
 		for i, column := range stmt.columns {
 			columns[i] = column.Name()
 		}
 
-		stmt.fields = AlignColumns(columns, unaligned)
+		// Align the cached fields of the given struct to the columns
+		var unaligned fields
+		stmt.fields, unaligned = cachedAlignment(elem.Type(), columns)
 
 		// If no fields were found and the number of fields matches the
 		// columns requested, then insert the struct's values as is.
@@ -253,14 +270,13 @@ func (stmt InsertStmt) Values(arg interface{}) InsertStmt {
 		// TODO check kind of elem directly?
 		elem0 := elem.Index(0)
 		if elem0.Kind() == reflect.Struct {
-			unaligned := SelectFieldsFromElem(elem.Type().Elem())
-
 			// TODO function to return names of columns
 			columns := make([]string, len(stmt.columns))
 			for i, column := range stmt.columns {
 				columns[i] = column.Name()
 			}
-			stmt.fields = AlignColumns(columns, unaligned)
+			var unaligned fields
+			stmt.fields, unaligned = cachedAlignment(elem.Type().Elem(), columns)
 
 			// If no fields were found and the number of fields matches the
 			// columns requested, then insert the struct's values as is.
@@ -363,6 +379,7 @@ func valuesMap(stmt InsertStmt, values Values) (fields, error) {
 			)
 		}
 		fields[i] = field{column: column} // TODO set index?
+		i += 1
 	}
 	return fields, nil
 }
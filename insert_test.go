@@ -182,6 +182,15 @@ func TestInsert(t *testing.T) {
 		"Hotspur",
 	)
 
+	// Every column of the Values is inserted
+	expect.SQL(
+		`INSERT INTO "users" ("id", "name", "password") VALUES ($1, $2, $3)`,
+		users.Insert().Values(Values{"id": 1, "name": "Hotspur", "password": "x"}),
+		1,
+		"Hotspur",
+		"x",
+	)
+
 	// A slice of Values is valid
 	vs := []Values{
 		{"name": "Totti"},
@@ -208,6 +217,56 @@ func TestInsert(t *testing.T) {
 	expect.Error(users.Insert().Values([]int64{1, 2, 3}))
 }
 
+var defaults = Table("defaults",
+	Column("id", Integer{PrimaryKey: true, Autoincrement: true}),
+	Column("name", String{}),
+	Column("score", Integer{Default: 10}),
+	Column("created_at", Timestamp{Default: Now()}),
+)
+
+type withDefaults struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name"`
+	Score     int64     `db:"score"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func TestInsertStmt_OmitDefaults(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+
+	// Columns with defaults are omitted when their fields are empty
+	expect.SQL(
+		`INSERT INTO "defaults" ("name") VALUES ($1)`,
+		defaults.Insert().OmitDefaults().Values(withDefaults{Name: "a"}),
+		"a",
+	)
+	expect.SQL(
+		`INSERT INTO "defaults" ("name", "score") VALUES ($1, $2)`,
+		defaults.Insert().OmitDefaults().Values(
+			withDefaults{Name: "a", Score: 5},
+		),
+		"a",
+		5,
+	)
+
+	// Columns without a default are never omitted
+	expect.SQL(
+		`INSERT INTO "defaults" ("name") VALUES ($1)`,
+		defaults.Insert().OmitDefaults().Values(withDefaults{}),
+		"",
+	)
+
+	// Without OmitDefaults all columns are inserted
+	expect.SQL(
+		`INSERT INTO "defaults" ("id", "name", "score", "created_at") VALUES ($1, $2, $3, $4)`,
+		defaults.Insert().Values(withDefaults{}),
+		0,
+		"",
+		0,
+		time.Time{},
+	)
+}
+
 func TestIsEmptyValue(t *testing.T) {
 	assert := assert.New(t)
 	// Expected empty values
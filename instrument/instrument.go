@@ -0,0 +1,209 @@
+package instrument
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aodin/aspect"
+)
+
+// Attribute keys of spans and metrics, following the OpenTelemetry
+// semantic conventions for database clients
+const (
+	SystemKey    = "db.system"
+	OperationKey = "db.operation"
+	TableKey     = "db.sql.table"
+	StatementKey = "db.statement"
+)
+
+// Names of the recorded metrics
+const (
+	StatementsMetric = "db.client.statements"  // counter
+	ErrorsMetric     = "db.client.errors"      // counter
+	DurationMetric   = "db.client.duration"    // histogram, in milliseconds
+	OpenMetric       = "db.client.connections" // gauge
+	InUseMetric      = "db.client.connections.in_use"
+	IdleMetric       = "db.client.connections.idle"
+	WaitCountMetric  = "db.client.connections.wait_count"
+	WaitTimeMetric   = "db.client.connections.wait_time" // in milliseconds
+)
+
+// Attributes are the key value pairs that describe a span or measurement
+type Attributes map[string]string
+
+// Span is a single executed statement
+type Span struct {
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes Attributes
+	Err        error
+}
+
+// Duration returns the duration of the span
+func (span Span) Duration() time.Duration {
+	return span.End.Sub(span.Start)
+}
+
+// Exporter receives the spans and measurements of instrumented
+// connections. Implementations must be safe for concurrent use.
+type Exporter interface {
+	ExportSpan(Span)
+	AddCounter(name string, delta int64, attrs Attributes)
+	RecordHistogram(name string, value float64, attrs Attributes)
+	RecordGauge(name string, value float64, attrs Attributes)
+}
+
+// Instrument returns a copy of the database connection pool whose
+// statements will be exported as spans and metrics. Transactions begun by
+// the copy are also instrumented.
+func Instrument(db *aspect.DB, exporter Exporter) *aspect.DB {
+	return db.WithHook(Hook(exporter))
+}
+
+// Hook returns an aspect.Hook that exports a span, a statement count, a
+// duration and any error for every statement.
+func Hook(exporter Exporter) aspect.Hook {
+	return func(event aspect.Event) {
+		operation := Operation(event.SQL)
+		tables := Tables(event.Stmt)
+		attrs := Attributes{
+			SystemKey:    aspect.DialectName(event.Dialect),
+			OperationKey: operation,
+		}
+		if len(tables) > 0 {
+			attrs[TableKey] = strings.Join(tables, ",")
+		}
+
+		// Metrics omit the SQL to limit their cardinality
+		metricAttrs := make(Attributes, len(attrs))
+		for key, value := range attrs {
+			metricAttrs[key] = value
+		}
+		exporter.AddCounter(StatementsMetric, 1, metricAttrs)
+		if event.Err != nil {
+			exporter.AddCounter(ErrorsMetric, 1, metricAttrs)
+		}
+		exporter.RecordHistogram(
+			DurationMetric, milliseconds(event.Duration), metricAttrs,
+		)
+
+		attrs[StatementKey] = event.SQL
+		name := operation
+		if len(tables) > 0 {
+			name += " " + tables[0]
+		}
+		exporter.ExportSpan(Span{
+			Name:       name,
+			Start:      event.Start,
+			End:        event.Start.Add(event.Duration),
+			Attributes: attrs,
+			Err:        event.Err,
+		})
+	}
+}
+
+// Operation returns the uppercase first keyword of the given SQL, such as
+// SELECT, ignoring any leading comments
+func Operation(s string) string {
+	for {
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "/*") {
+			end := strings.Index(s, "*/")
+			if end == -1 {
+				return ""
+			}
+			s = s[end+2:]
+		} else if strings.HasPrefix(s, "--") {
+			end := strings.Index(s, "\n")
+			if end == -1 {
+				return ""
+			}
+			s = s[end+1:]
+		} else {
+			break
+		}
+	}
+	if end := strings.IndexFunc(s, isSeparator); end != -1 {
+		s = s[:end]
+	}
+	return strings.ToUpper(s)
+}
+
+func isSeparator(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '(' || r == ';'
+}
+
+// Tables returns the sorted names of the tables of the given statement, if
+// they are known
+func Tables(stmt aspect.Executable) []string {
+	var tables []*aspect.TableElem
+	switch s := stmt.(type) {
+	case interface {
+		Tables() []*aspect.TableElem
+	}:
+		tables = s.Tables()
+	case interface {
+		Table() *aspect.TableElem
+	}:
+		tables = append(tables, s.Table())
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		if table == nil || seen[table.Name()] {
+			continue
+		}
+		seen[table.Name()] = true
+		names = append(names, table.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Statser is implemented by connection pools with statistics, such as
+// aspect.DB and sql.DB
+type Statser interface {
+	Stats() sql.DBStats
+}
+
+// RecordStats records the current statistics of the connection pool as
+// gauges with the given attributes.
+func RecordStats(exporter Exporter, pool Statser, attrs Attributes) {
+	stats := pool.Stats()
+	exporter.RecordGauge(OpenMetric, float64(stats.OpenConnections), attrs)
+	exporter.RecordGauge(InUseMetric, float64(stats.InUse), attrs)
+	exporter.RecordGauge(IdleMetric, float64(stats.Idle), attrs)
+	exporter.RecordGauge(WaitCountMetric, float64(stats.WaitCount), attrs)
+	exporter.RecordGauge(WaitTimeMetric, milliseconds(stats.WaitDuration), attrs)
+}
+
+// CollectStats records the statistics of the connection pool at the given
+// interval until the returned function is called.
+func CollectStats(exporter Exporter, pool Statser, attrs Attributes, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				RecordStats(exporter, pool, attrs)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
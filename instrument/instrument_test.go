@@ -0,0 +1,84 @@
+package instrument
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aodin/aspect"
+	_ "github.com/aodin/aspect/sqlite3"
+)
+
+var teams = aspect.Table("teams",
+	aspect.Column("id", aspect.Integer{NotNull: true}),
+	aspect.Column("name", aspect.String{}),
+	aspect.PrimaryKey("id"),
+)
+
+var players = aspect.Table("players",
+	aspect.Column("id", aspect.Integer{NotNull: true}),
+	aspect.ForeignKey("team_id", teams.C["id"], aspect.Integer{}),
+	aspect.PrimaryKey("id"),
+)
+
+func TestOperation(t *testing.T) {
+	assert.Equal(t, "SELECT", Operation(`SELECT "teams"."id" FROM "teams"`))
+	assert.Equal(t, "INSERT", Operation("/* api */ insert INTO teams"))
+	assert.Equal(t, "WITH", Operation("-- comment\nWITH(x)"))
+	assert.Equal(t, "", Operation("/* unterminated"))
+}
+
+func TestTables(t *testing.T) {
+	assert.Equal(t, []string{"teams"}, Tables(teams.Insert()))
+	assert.Equal(t, []string{"teams"}, Tables(teams.Delete()))
+	assert.Equal(t,
+		[]string{"players", "teams"},
+		Tables(teams.Select().JoinOn(players, teams.C["id"].Equals(players.C["team_id"]))),
+	)
+	assert.Nil(t, Tables(aspect.Drop(teams)))
+}
+
+func TestInstrument(t *testing.T) {
+	db, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer db.Close()
+
+	recorder := NewRecorder()
+	conn := Instrument(db, recorder)
+
+	conn.MustExecute(teams.Create())
+	conn.MustExecute(teams.Insert().Values(aspect.Values{"id": 1, "name": "Roma"}))
+	var names []string
+	require.Nil(t, conn.QueryAll(aspect.Select(teams.C["name"]), &names))
+	_, err = conn.Execute(players.Insert())
+	assert.NotNil(t, err)
+
+	spans := recorder.Spans()
+	require.Equal(t, 4, len(spans))
+	assert.Equal(t, "CREATE teams", spans[0].Name)
+	assert.Equal(t, "INSERT teams", spans[1].Name)
+	assert.Equal(t, Attributes{
+		SystemKey:    "sqlite3",
+		OperationKey: "SELECT",
+		TableKey:     "teams",
+		StatementKey: `SELECT "teams"."name" FROM "teams"`,
+	}, spans[2].Attributes)
+	assert.True(t, spans[2].Duration() >= 0)
+	assert.NotNil(t, spans[3].Err)
+
+	assert.EqualValues(t, 4, recorder.Counter(StatementsMetric))
+	assert.EqualValues(t, 1, recorder.Counter(ErrorsMetric))
+	assert.Equal(t, 4, len(recorder.Histogram(DurationMetric)))
+
+	// Pool statistics are recorded as gauges
+	RecordStats(recorder, conn, Attributes{SystemKey: "sqlite3"})
+	open, ok := recorder.Gauge(OpenMetric)
+	assert.True(t, ok)
+	assert.True(t, open >= 1)
+
+	stop := CollectStats(recorder, conn, nil, time.Millisecond)
+	stop()
+	stop()
+}
@@ -0,0 +1,86 @@
+package instrument
+
+import "sync"
+
+// Recorder is an in-memory Exporter, intended for tests. Measurements are
+// recorded by name regardless of their attributes.
+type Recorder struct {
+	mu         sync.Mutex
+	spans      []Span
+	counters   map[string]int64
+	histograms map[string][]float64
+	gauges     map[string]float64
+}
+
+var _ Exporter = &Recorder{}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		counters:   make(map[string]int64),
+		histograms: make(map[string][]float64),
+		gauges:     make(map[string]float64),
+	}
+}
+
+// ExportSpan records the span
+func (r *Recorder) ExportSpan(span Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// AddCounter adds the delta to the named counter
+func (r *Recorder) AddCounter(name string, delta int64, attrs Attributes) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name] += delta
+}
+
+// RecordHistogram records the value of the named histogram
+func (r *Recorder) RecordHistogram(name string, value float64, attrs Attributes) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.histograms[name] = append(r.histograms[name], value)
+}
+
+// RecordGauge sets the current value of the named gauge
+func (r *Recorder) RecordGauge(name string, value float64, attrs Attributes) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = value
+}
+
+// Spans returns the recorded spans
+func (r *Recorder) Spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := make([]Span, len(r.spans))
+	copy(spans, r.spans)
+	return spans
+}
+
+// Counter returns the total of the named counter
+func (r *Recorder) Counter(name string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counters[name]
+}
+
+// Histogram returns the recorded values of the named histogram
+func (r *Recorder) Histogram(name string) []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]float64, len(r.histograms[name]))
+	copy(values, r.histograms[name])
+	return values
+}
+
+// Gauge returns the current value of the named gauge and whether it has
+// been recorded
+func (r *Recorder) Gauge(name string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.gauges[name]
+	return value, ok
+}
@@ -12,9 +12,11 @@ type Integer struct {
 	Unique        bool
 	PrimaryKey    bool
 	Autoincrement bool
+	Default       interface{}
 }
 
 var _ Type = Integer{}
+var _ Defaulter = Integer{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s Integer) Create(d Dialect) (string, error) {
@@ -35,18 +37,30 @@ func (s Integer) Create(d Dialect) (string, error) {
 	if s.Unique {
 		attrs = append(attrs, "UNIQUE")
 	}
+	if HasDefaultValue(s.Default) {
+		def, err := CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		attrs = append(attrs, def)
+	}
 	if len(attrs) > 0 {
 		compiled += fmt.Sprintf(" %s", strings.Join(attrs, " "))
 	}
 	return compiled, nil
 }
 
+// HasDefault implements the Defaulter interface.
+func (s Integer) HasDefault() bool {
+	return s.Autoincrement || HasDefaultValue(s.Default)
+}
+
 func (s Integer) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s Integer) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s Integer) IsUnique() bool {
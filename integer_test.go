@@ -19,6 +19,11 @@ func TestInteger(t *testing.T) {
 		"INTEGER PRIMARY KEY AUTOINCREMENT",
 		Integer{PrimaryKey: true, Autoincrement: true},
 	)
+	expect.Create("INTEGER NOT NULL DEFAULT 0", Integer{NotNull: true, Default: 0})
+
+	assert.Equal(false, Integer{}.HasDefault())
+	assert.Equal(true, Integer{Default: 1}.HasDefault())
+	assert.Equal(true, Integer{Autoincrement: true}.HasDefault())
 
 	assert.Equal(false, Integer{}.IsPrimaryKey())
 	assert.Equal(false, Integer{}.IsUnique())
@@ -0,0 +1,131 @@
+package aspect
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Escaper is an optional interface for dialects that require additional
+// escaping of string literals, such as MySQL's backslash escapes.
+type Escaper interface {
+	EscapeString(string) string
+}
+
+// TimestampLayout is the format used to output time.Time literals
+const TimestampLayout = "2006-01-02 15:04:05.999999-07:00"
+
+// RawClause outputs its SQL as is. It must never be given user input, since
+// its contents will not be escaped.
+type RawClause struct {
+	SQL string
+}
+
+// String returns the RawClause's SQL.
+func (c RawClause) String() string {
+	return c.SQL
+}
+
+// Compile returns the RawClause's SQL regardless of dialect.
+func (c RawClause) Compile(d Dialect, params *Parameters) (string, error) {
+	return c.SQL, nil
+}
+
+// Raw creates a clause that will output the given SQL without escaping.
+func Raw(sql string) RawClause {
+	return RawClause{SQL: sql}
+}
+
+// LiteralClause outputs its Value as an escaped SQL literal rather than
+// as a parameter. It is used in statements that do not accept parameters,
+// such as the DEFAULT clause of CREATE TABLE statements.
+type LiteralClause struct {
+	Value interface{}
+}
+
+// String returns the LiteralClause's SQL using the default dialect.
+func (c LiteralClause) String() string {
+	compiled, _ := c.Compile(&defaultDialect{}, Params())
+	return compiled
+}
+
+// Compile outputs the escaped literal using the given dialect.
+func (c LiteralClause) Compile(d Dialect, params *Parameters) (string, error) {
+	return CompileLiteral(d, c.Value)
+}
+
+// Literal creates a clause that will output the given value as an escaped
+// SQL literal.
+func Literal(value interface{}) LiteralClause {
+	return LiteralClause{Value: value}
+}
+
+// escapeString quotes the given string, doubling any single quotes and
+// applying any additional escaping required by the dialect.
+func escapeString(d Dialect, s string) string {
+	if escaper, ok := d.(Escaper); ok {
+		s = escaper.EscapeString(s)
+	}
+	return fmt.Sprintf(`'%s'`, strings.Replace(s, `'`, `''`, -1))
+}
+
+// CompileLiteral outputs the given Go value as an escaped SQL literal using
+// the given dialect. Nil values and nil pointers are output as NULL and
+// driver.Valuer implementations are converted before they are escaped.
+func CompileLiteral(d Dialect, value interface{}) (string, error) {
+	// Dereference any pointers
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "NULL", nil
+		}
+		if valuer, ok := v.Interface().(driver.Valuer); ok {
+			return compileValuer(d, valuer)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "NULL", nil
+	}
+	value = v.Interface()
+
+	switch t := value.(type) {
+	case time.Time:
+		return escapeString(d, t.Format(TimestampLayout)), nil
+	case []byte:
+		return escapeString(d, string(t)), nil
+	case driver.Valuer:
+		return compileValuer(d, t)
+	}
+
+	// Use the kind to support named types, such as: type Status string
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	case reflect.String:
+		return escapeString(d, v.String()), nil
+	}
+	return "", fmt.Errorf(
+		"aspect: unable to output a value of type %T as a literal", value,
+	)
+}
+
+func compileValuer(d Dialect, valuer driver.Valuer) (string, error) {
+	value, err := valuer.Value()
+	if err != nil {
+		return "", err
+	}
+	return CompileLiteral(d, value)
+}
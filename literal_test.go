@@ -0,0 +1,81 @@
+package aspect
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type status string
+
+type escapeDialect struct {
+	defaultDialect
+}
+
+func (d *escapeDialect) EscapeString(s string) string {
+	return s + "!"
+}
+
+func TestLiteral(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+
+	expect.SQL(`NULL`, Literal(nil))
+	expect.SQL(`TRUE`, Literal(true))
+	expect.SQL(`FALSE`, Literal(False))
+	expect.SQL(`-12`, Literal(-12))
+	expect.SQL(`12`, Literal(uint8(12)))
+	expect.SQL(`1.5`, Literal(1.5))
+	expect.SQL(`'O''Neil'`, Literal("O'Neil"))
+	expect.SQL(`'active'`, Literal(status("active")))
+	expect.SQL(`'bytes'`, Literal([]byte("bytes")))
+	expect.SQL(
+		`'2014-01-01 12:00:00.5+00:00'`,
+		Literal(time.Date(2014, 1, 1, 12, 0, 0, 5e8, time.UTC)),
+	)
+
+	// Pointers are dereferenced and driver.Valuers converted
+	var nilString *string
+	expect.SQL(`NULL`, Literal(nilString))
+	expect.SQL(`''`, Literal(Blank))
+	expect.SQL(`NULL`, Literal(sql.NullString{}))
+	expect.SQL(`'a'`, Literal(sql.NullString{String: "a", Valid: true}))
+	expect.SQL(`NULL`, Literal(&sql.NullInt64{}))
+
+	expect.Error(Literal(struct{}{}))
+	expect.Error(Literal([]int{1}))
+
+	// Dialects may provide additional escaping
+	escaped, err := Literal("a").Compile(&escapeDialect{}, Params())
+	assert.Nil(t, err)
+	assert.Equal(t, `'a!'`, escaped)
+
+	// Raw SQL is output as is
+	expect.SQL(`now()`, Raw(`now()`))
+}
+
+func TestCompileDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(HasDefaultValue(nil))
+	assert.False(HasDefaultValue((*bool)(nil)))
+	assert.True(HasDefaultValue(False))
+	assert.True(HasDefaultValue(0))
+
+	d := &defaultDialect{}
+	compiled, err := CompileDefault(d, nil)
+	assert.Nil(err)
+	assert.Equal("", compiled)
+
+	compiled, err = CompileDefault(d, "a")
+	assert.Nil(err)
+	assert.Equal("DEFAULT 'a'", compiled)
+
+	compiled, err = CompileDefault(d, Now())
+	assert.Nil(err)
+	assert.Equal("DEFAULT (CURRENT_TIMESTAMP)", compiled)
+
+	_, err = CompileDefault(d, struct{}{})
+	assert.NotNil(err)
+}
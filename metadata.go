@@ -0,0 +1,194 @@
+package aspect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetaData is a registry of tables. Tables can be added to the registry
+// with its Add method or by including the MetaData in the Table constructor:
+//  var meta = aspect.NewMetaData()
+//
+//  var users = aspect.Table("users",
+//      meta,
+//      aspect.Column("id", aspect.Integer{PrimaryKey: true}),
+//  )
+// The registry will order its tables by their foreign key dependencies,
+// allowing an entire schema to be created or dropped at once.
+type MetaData struct {
+	tables []*TableElem
+}
+
+var _ TableModifier = &MetaData{}
+
+// qualifiedName returns the table's name, prefixed by its schema if one
+// has been set.
+func qualifiedName(table *TableElem) string {
+	if table.schema != "" {
+		return fmt.Sprintf("%s.%s", table.schema, table.name)
+	}
+	return table.name
+}
+
+// Add registers the given tables with the MetaData. An error will be
+// returned if a table with the same name already exists in the registry.
+func (meta *MetaData) Add(tables ...*TableElem) error {
+	for _, table := range tables {
+		if table == nil {
+			return fmt.Errorf("aspect: cannot add a nil table to MetaData")
+		}
+		name := qualifiedName(table)
+		if meta.Table(name) != nil {
+			return fmt.Errorf(
+				"aspect: a table named '%s' already exists in the MetaData",
+				name,
+			)
+		}
+		meta.tables = append(meta.tables, table)
+	}
+	return nil
+}
+
+// Modify implements the TableModifier interface. It registers the given
+// table with the MetaData. When given to Table, the table is registered
+// after all of its other elements.
+func (meta *MetaData) Modify(t *TableElem) error {
+	return meta.Add(t)
+}
+
+// Table returns the table with the given name, or nil if no table with that
+// name exists. Tables with a schema must be requested by their qualified
+// name, such as "audit.logs".
+func (meta *MetaData) Table(name string) *TableElem {
+	for _, table := range meta.tables {
+		if qualifiedName(table) == name {
+			return table
+		}
+	}
+	return nil
+}
+
+// Tables returns the registered tables in the order they were added.
+func (meta *MetaData) Tables() []*TableElem {
+	tables := make([]*TableElem, len(meta.tables))
+	copy(tables, meta.tables)
+	return tables
+}
+
+// Sorted returns the registered tables ordered so that every table follows
+// the tables its foreign keys reference. Tables without dependencies keep
+// their registration order. References to tables outside the MetaData and
+// self-referencing foreign keys are ignored. An error will be returned if
+// the foreign keys contain a cycle.
+func (meta *MetaData) Sorted() ([]*TableElem, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*TableElem]int)
+	sorted := make([]*TableElem, 0, len(meta.tables))
+
+	// The path of the current depth-first search is kept to report cycles
+	var path []*TableElem
+
+	var visit func(*TableElem) error
+	visit = func(table *TableElem) error {
+		switch state[table] {
+		case visited:
+			return nil
+		case visiting:
+			// Report the cycle starting from the first occurrence of table
+			var names []string
+			for i, t := range path {
+				if t == table {
+					for _, cycle := range path[i:] {
+						names = append(names, qualifiedName(cycle))
+					}
+					break
+				}
+			}
+			names = append(names, qualifiedName(table))
+			return fmt.Errorf(
+				"aspect: foreign keys contain a cycle: %s",
+				strings.Join(names, " -> "),
+			)
+		}
+
+		state[table] = visiting
+		path = append(path, table)
+		for _, fk := range table.ForeignKeys() {
+			ref := fk.ReferencesTable()
+			if ref == nil || ref == table || !meta.contains(ref) {
+				continue
+			}
+			if err := visit(ref); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[table] = visited
+		sorted = append(sorted, table)
+		return nil
+	}
+
+	for _, table := range meta.tables {
+		if err := visit(table); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// contains returns true if the given table has been registered.
+func (meta *MetaData) contains(table *TableElem) bool {
+	for _, t := range meta.tables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAll creates every registered table in dependency order using the
+// given connection. Tables that already exist will be skipped.
+func (meta *MetaData) CreateAll(conn Connection) error {
+	tables, err := meta.Sorted()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if _, err := conn.Execute(table.Create().IfNotExists()); err != nil {
+			return fmt.Errorf(
+				"aspect: failed to create table '%s': %s",
+				qualifiedName(table),
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// DropAll drops every registered table in reverse dependency order using
+// the given connection. Tables that do not exist will be skipped.
+func (meta *MetaData) DropAll(conn Connection) error {
+	tables, err := meta.Sorted()
+	if err != nil {
+		return err
+	}
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, err := conn.Execute(tables[i].Drop().IfExists()); err != nil {
+			return fmt.Errorf(
+				"aspect: failed to drop table '%s': %s",
+				qualifiedName(tables[i]),
+				err,
+			)
+		}
+	}
+	return nil
+}
+
+// NewMetaData creates an empty MetaData registry.
+func NewMetaData() *MetaData {
+	return &MetaData{}
+}
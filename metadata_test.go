@@ -0,0 +1,96 @@
+package aspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaData(t *testing.T) {
+	meta := NewMetaData()
+
+	// Tables can be registered through the Table constructor
+	companies := Table("companies",
+		meta,
+		Column("id", Integer{PrimaryKey: true}),
+	)
+	employees := Table("employees",
+		meta,
+		Column("id", Integer{PrimaryKey: true}),
+		ForeignKey("company_id", companies.C["id"], Integer{}),
+		SelfForeignKey("manager_id", "id", Integer{}),
+	)
+
+	// Or added after declaration - out of dependency order
+	assignments := Table("assignments",
+		ForeignKey("employee_id", employees.C["id"], Integer{}),
+		ForeignKey("project_id", projects.C["id"], Integer{}),
+	)
+	require.Nil(t, meta.Add(assignments, projects))
+
+	assert.Equal(t, companies, meta.Table("companies"))
+	assert.Nil(t, meta.Table("missing"))
+	assert.Equal(t,
+		[]*TableElem{companies, employees, assignments, projects},
+		meta.Tables(),
+	)
+
+	sorted, err := meta.Sorted()
+	require.Nil(t, err)
+	assert.Equal(t,
+		[]*TableElem{companies, employees, projects, assignments},
+		sorted,
+	)
+
+	// Duplicate names are not allowed
+	assert.NotNil(t, meta.Add(Table("companies")))
+	assert.Panics(t, func() {
+		Table("employees", meta)
+	},
+		"failed to panic when a duplicate table was registered",
+	)
+
+	// Tables in different schemas may share a name
+	require.Nil(t, meta.Add(Table("companies", Schema("archive"))))
+	assert.NotNil(t, meta.Table("archive.companies"))
+
+	// The schema is known before the table is registered
+	assert.NotPanics(t, func() {
+		Table("employees", meta, Schema("archive"))
+	})
+	assert.NotNil(t, meta.Table("archive.employees"))
+
+	// Tables that fail to build are not registered
+	assert.Panics(t, func() {
+		Table("invoices",
+			meta,
+			Column("id", Integer{}),
+			Column("id", Integer{}),
+		)
+	})
+	assert.Nil(t, meta.Table("invoices"))
+}
+
+var projects = Table("projects",
+	Column("id", Integer{PrimaryKey: true}),
+)
+
+func TestMetaData_Cycle(t *testing.T) {
+	a := Table("a", Column("id", Integer{PrimaryKey: true}))
+	b := Table("b",
+		Column("id", Integer{PrimaryKey: true}),
+		ForeignKey("a_id", a.C["id"], Integer{}),
+	)
+	require.Nil(t, ForeignKey("b_id", b.C["id"], Integer{}).Modify(a))
+
+	meta := NewMetaData()
+	require.Nil(t, meta.Add(a, b))
+
+	_, err := meta.Sorted()
+	require.NotNil(t, err)
+	assert.Equal(t,
+		"aspect: foreign keys contain a cycle: a -> b -> a",
+		err.Error(),
+	)
+}
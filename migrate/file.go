@@ -0,0 +1,188 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aodin/aspect"
+)
+
+// Annotations of goose SQL migration files
+const (
+	annotationUp             = "-- +goose Up"
+	annotationDown           = "-- +goose Down"
+	annotationStatementBegin = "-- +goose StatementBegin"
+	annotationStatementEnd   = "-- +goose StatementEnd"
+	annotationNoTransaction  = "-- +goose NO TRANSACTION"
+)
+
+// ParseDir parses every goose SQL migration file in the given directory.
+// Files must be named with their version, such as 20150102150405_users.sql.
+// All other files, including goose's Go migrations, are ignored.
+func ParseDir(dir string) ([]Migration, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != ".sql" {
+			continue
+		}
+		migration, err := ParseFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+// ParseFile parses the goose SQL migration file at the given path.
+func ParseFile(path string) (migration Migration, err error) {
+	base := filepath.Base(path)
+	parts := strings.SplitN(strings.TrimSuffix(base, ".sql"), "_", 2)
+	if migration.Version, err = strconv.ParseInt(parts[0], 10, 64); err != nil || migration.Version < 1 {
+		err = fmt.Errorf(
+			"migrate: file %s does not begin with a valid version", base,
+		)
+		return
+	}
+	if len(parts) == 2 {
+		migration.Name = parts[1]
+	}
+	migration.Source = path
+
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	up, down, noTx, err := parseSQL(f)
+	if err != nil {
+		err = fmt.Errorf("migrate: failed to parse %s: %s", base, err)
+		return
+	}
+	migration.Up = rawStatements(up)
+	migration.Down = rawStatements(down)
+	migration.NoTransaction = noTx
+	return
+}
+
+// ParseSQL splits a goose SQL migration into its up and down statements.
+// Statements end with a semicolon at the end of a line, unless they are
+// wrapped in StatementBegin and StatementEnd annotations.
+func ParseSQL(r io.Reader) (up, down []string, err error) {
+	up, down, _, err = parseSQL(r)
+	return
+}
+
+// parseSQL parses a goose SQL migration and also reports whether it has
+// the NO TRANSACTION annotation
+func parseSQL(r io.Reader) (up, down []string, noTx bool, err error) {
+	var current *[]string
+	var buffer []string
+	var inStatement bool
+
+	// unterminated returns an error if a statement is still open at the
+	// given annotation
+	unterminated := func(at string) error {
+		if inStatement {
+			return fmt.Errorf(
+				"'%s' was found before '%s'", at, annotationStatementEnd,
+			)
+		}
+		if len(buffer) > 0 {
+			return fmt.Errorf(
+				"a statement before '%s' is missing its terminating semicolon",
+				at,
+			)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, annotationNoTransaction):
+			noTx = true
+			continue
+		case strings.HasPrefix(trimmed, annotationUp):
+			if err = unterminated(annotationUp); err != nil {
+				return
+			}
+			current = &up
+			continue
+		case strings.HasPrefix(trimmed, annotationDown):
+			if err = unterminated(annotationDown); err != nil {
+				return
+			}
+			current = &down
+			continue
+		case strings.HasPrefix(trimmed, annotationStatementBegin):
+			if err = unterminated(annotationStatementBegin); err != nil {
+				return
+			}
+			inStatement = true
+			continue
+		case strings.HasPrefix(trimmed, annotationStatementEnd):
+			inStatement = false
+			if current != nil && len(buffer) > 0 {
+				*current = append(*current, strings.Join(buffer, "\n"))
+			}
+			buffer = nil
+			continue
+		}
+
+		// Skip comments and blank lines outside of statements
+		if current == nil || (len(buffer) == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--"))) {
+			continue
+		}
+		buffer = append(buffer, line)
+
+		if !inStatement && strings.HasSuffix(trimmed, ";") {
+			*current = append(*current, strings.Join(buffer, "\n"))
+			buffer = nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if current == nil {
+		err = fmt.Errorf("no '%s' annotation was found", annotationUp)
+		return
+	}
+	if inStatement {
+		err = fmt.Errorf(
+			"'%s' is missing its '%s'",
+			annotationStatementBegin, annotationStatementEnd,
+		)
+		return
+	}
+	if len(buffer) > 0 {
+		err = fmt.Errorf("a statement is missing its terminating semicolon")
+	}
+	return
+}
+
+// rawStatements creates a Func that executes the given SQL statements
+func rawStatements(stmts []string) Func {
+	if len(stmts) == 0 {
+		return nil
+	}
+	executables := make([]aspect.Executable, len(stmts))
+	for i, stmt := range stmts {
+		executables[i] = aspect.Raw(stmt)
+	}
+	return Statements(executables...)
+}
@@ -0,0 +1,442 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aodin/aspect"
+)
+
+// Versions is goose's table of applied migrations, which allows a database
+// to be migrated by either goose or this package. It will be created with
+// goose's schema for the dialect if it does not exist when a migration is
+// first applied or rolled back.
+var Versions = aspect.Table("goose_db_version",
+	aspect.Column("id", aspect.BigInt{NotNull: true}),
+	aspect.Column("version_id", aspect.BigInt{NotNull: true}),
+	aspect.Column("is_applied", aspect.Boolean{NotNull: true}),
+	aspect.Column("tstamp", aspect.Timestamp{}),
+	aspect.PrimaryKey("id"),
+)
+
+// createVersions are goose's statements to create the versions table,
+// keyed by dialect name
+var createVersions = map[string]string{
+	"postgres": `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id serial NOT NULL,
+    version_id bigint NOT NULL,
+    is_applied boolean NOT NULL,
+    tstamp timestamp NULL default now(),
+    PRIMARY KEY(id)
+)`,
+	"mysql": `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id serial NOT NULL,
+    version_id bigint NOT NULL,
+    is_applied boolean NOT NULL,
+    tstamp timestamp NULL default now(),
+    PRIMARY KEY(id)
+)`,
+	"sqlite3": `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version_id INTEGER NOT NULL,
+    is_applied INTEGER NOT NULL,
+    tstamp TIMESTAMP DEFAULT (datetime('now'))
+)`,
+}
+
+// Migration is a single versioned schema change. Its Source is the path of
+// its SQL file, or empty if it was declared in Go. Migrations with
+// NoTransaction set are run directly on the connection, as is required by
+// statements such as CREATE INDEX CONCURRENTLY.
+type Migration struct {
+	Version       int64
+	Name          string
+	Source        string
+	Up            Func
+	Down          Func
+	NoTransaction bool
+}
+
+// Status is the state of a single migration.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type version struct {
+	ID        int64      `db:"id"`
+	Version   int64      `db:"version_id"`
+	IsApplied bool       `db:"is_applied"`
+	AppliedAt *time.Time `db:"tstamp"`
+}
+
+func (v version) appliedAt() time.Time {
+	if v.AppliedAt == nil {
+		return time.Time{}
+	}
+	return *v.AppliedAt
+}
+
+// Migrator applies and rolls back migrations. Unless it has NoTransaction
+// set, each migration is run in its own transaction along with the update
+// of the versions table.
+type Migrator struct {
+	conn       aspect.Connection
+	migrations []Migration
+	created    bool // whether the versions table is known to exist
+}
+
+// New creates a Migrator for the given connection using the SQL migrations
+// in the given directory and all migrations registered in Go. The
+// directory may be empty if only Go migrations are used.
+func New(conn aspect.Connection, dir string) (*Migrator, error) {
+	var migrations []Migration
+	if dir != "" {
+		var err error
+		if migrations, err = ParseDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	for _, migration := range registry {
+		migrations = append(migrations, migration)
+	}
+	return NewWith(conn, migrations...)
+}
+
+// NewWith creates a Migrator for the given connection using only the given
+// migrations. Versions must be unique.
+func NewWith(conn aspect.Connection, migrations ...Migration) (*Migrator, error) {
+	sort.Sort(byVersion(migrations))
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf(
+				"migrate: duplicate migrations with version %d",
+				migrations[i].Version,
+			)
+		}
+	}
+	return &Migrator{conn: conn, migrations: migrations}, nil
+}
+
+// ensureVersions creates the versions table the first time a migration is
+// applied or rolled back
+func (m *Migrator) ensureVersions() error {
+	if m.created {
+		return nil
+	}
+	if err := createVersionsTable(m.conn); err != nil {
+		return fmt.Errorf(
+			"migrate: failed to create the versions table: %s", err,
+		)
+	}
+	m.created = true
+	return nil
+}
+
+// versionsExist reports whether the versions table exists. Dialects that
+// cannot list their tables are assumed to have it.
+func (m *Migrator) versionsExist() (bool, error) {
+	if m.created {
+		return true, nil
+	}
+	dialect, err := aspect.ConnectionDialect(m.conn)
+	if err != nil {
+		return false, err
+	}
+	reflector, ok := dialect.(aspect.Reflector)
+	if !ok {
+		return true, nil
+	}
+	names, err := reflector.TableNames(m.conn)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range names {
+		if name == Versions.Name() {
+			m.created = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createVersionsTable creates goose's versions table if it does not exist,
+// along with the version zero row that goose expects
+func createVersionsTable(conn aspect.Connection) error {
+	dialect, err := aspect.ConnectionDialect(conn)
+	if err != nil {
+		return err
+	}
+	create, ok := createVersions[aspect.DialectName(dialect)]
+	if !ok {
+		return fmt.Errorf("the dialect %T is not supported by goose", dialect)
+	}
+	if _, err := conn.Execute(aspect.Raw(create)); err != nil {
+		return err
+	}
+	var ids []int64
+	stmt := aspect.Select(Versions.C["id"]).Where(
+		Versions.C["version_id"].Equals(0),
+	)
+	if err := conn.QueryAll(stmt, &ids); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return nil
+	}
+	_, err = conn.Execute(Versions.Insert().Values(aspect.Values{
+		"version_id": 0,
+		"is_applied": true,
+	}))
+	return err
+}
+
+// Migrations returns all known migrations in version order.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Status returns the state of every known migration in version order.
+// Applied versions without a known migration are included last.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i].Migration = migration
+		if v, ok := applied[migration.Version]; ok {
+			statuses[i].Applied = true
+			statuses[i].AppliedAt = v.appliedAt()
+			delete(applied, migration.Version)
+		}
+	}
+
+	var missing []Status
+	for _, v := range applied {
+		missing = append(missing, Status{
+			Migration: Migration{Version: v.Version},
+			Applied:   true,
+			AppliedAt: v.appliedAt(),
+		})
+	}
+	sort.Sort(statusByVersion(missing))
+	return append(statuses, missing...), nil
+}
+
+// Version returns the latest applied version, or zero if no migrations
+// have been applied.
+func (m *Migrator) Version() (int64, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for v := range applied {
+		if v > latest {
+			latest = v
+		}
+	}
+	return latest, nil
+}
+
+// Up applies all pending migrations in version order.
+func (m *Migrator) Up() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := m.up(migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down rolls back the latest applied migration.
+func (m *Migrator) Down() error {
+	migration, err := m.latest()
+	if err != nil {
+		return err
+	}
+	return m.down(migration)
+}
+
+// Redo rolls back and then re-applies the latest applied migration.
+func (m *Migrator) Redo() error {
+	migration, err := m.latest()
+	if err != nil {
+		return err
+	}
+	if err := m.down(migration); err != nil {
+		return err
+	}
+	return m.up(migration)
+}
+
+// To applies or rolls back migrations until the given version is the latest
+// applied version. Migrations after the version are rolled back in reverse
+// order and pending migrations up to and including the version are
+// applied. A version of zero will roll back all migrations.
+func (m *Migrator) To(target int64) error {
+	if target != 0 && m.find(target) == nil {
+		return fmt.Errorf("migrate: no migration with version %d", target)
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; ok && migration.Version > target {
+			if err := m.down(migration); err != nil {
+				return err
+			}
+		}
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= target {
+			if err := m.up(migration); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) up(migration Migration) error {
+	if err := m.ensureVersions(); err != nil {
+		return err
+	}
+	return m.run(migration, migration.Up, Versions.Insert().Values(aspect.Values{
+		"version_id": migration.Version,
+		"is_applied": true,
+	}))
+}
+
+// down rolls back the given migration. As in goose, a row that marks the
+// version as not applied is inserted rather than deleting its rows.
+func (m *Migrator) down(migration Migration) error {
+	if err := m.ensureVersions(); err != nil {
+		return err
+	}
+	return m.run(migration, migration.Down, Versions.Insert().Values(aspect.Values{
+		"version_id": migration.Version,
+		"is_applied": false,
+	}))
+}
+
+// run executes the given function and versions statement in a single
+// transaction, which is rolled back if either fails. Migrations with
+// NoTransaction set are run directly on the connection.
+func (m *Migrator) run(migration Migration, f Func, stmt aspect.Executable) (err error) {
+	var tx aspect.Transaction
+	if migration.NoTransaction {
+		tx = noTransaction{m.conn}
+	} else if tx, err = m.conn.Begin(); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			err = fmt.Errorf(
+				"migrate: migration %d %s failed: %s",
+				migration.Version, migration.Name, err,
+			)
+		}
+	}()
+	if f != nil {
+		if err = f(tx); err != nil {
+			return
+		}
+	}
+	if _, err = tx.Execute(stmt); err != nil {
+		return
+	}
+	return tx.Commit()
+}
+
+// latest returns the latest applied migration
+func (m *Migrator) latest() (Migration, error) {
+	current, err := m.Version()
+	if err != nil {
+		return Migration{}, err
+	}
+	if current == 0 {
+		return Migration{}, fmt.Errorf("migrate: no migrations have been applied")
+	}
+	migration := m.find(current)
+	if migration == nil {
+		return Migration{}, fmt.Errorf(
+			"migrate: no migration with the applied version %d", current,
+		)
+	}
+	return *migration, nil
+}
+
+func (m *Migrator) find(v int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == v {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// applied returns the applied versions keyed by version. As in goose, the
+// latest row of each version decides whether it is applied, and the
+// version zero row created with the table is ignored. No versions are
+// applied if the table does not exist yet.
+func (m *Migrator) applied() (map[int64]version, error) {
+	applied := make(map[int64]version)
+	if exists, err := m.versionsExist(); err != nil || !exists {
+		return applied, err
+	}
+	var versions []version
+	stmt := Versions.Select().OrderBy(Versions.C["id"].Desc())
+	if err := m.conn.QueryAll(stmt, &versions); err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool)
+	for _, v := range versions {
+		if seen[v.Version] {
+			continue
+		}
+		seen[v.Version] = true
+		if v.Version != 0 && v.IsApplied {
+			applied[v.Version] = v
+		}
+	}
+	return applied, nil
+}
+
+// noTransaction runs the statements of a migration directly on the
+// connection. Committing and rolling back do nothing.
+type noTransaction struct {
+	aspect.Connection
+}
+
+func (noTransaction) Commit() error                  { return nil }
+func (noTransaction) CommitIf(commit *bool) error    { return nil }
+func (noTransaction) MustCommitIf(commit *bool) bool { return false }
+func (noTransaction) Rollback() error                { return nil }
+func (noTransaction) MustRollbackIf(rollback *bool)  {}
+
+type byVersion []Migration
+
+func (m byVersion) Len() int           { return len(m) }
+func (m byVersion) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m byVersion) Less(i, j int) bool { return m[i].Version < m[j].Version }
+
+type statusByVersion []Status
+
+func (s statusByVersion) Len() int           { return len(s) }
+func (s statusByVersion) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s statusByVersion) Less(i, j int) bool { return s[i].Version < s[j].Version }
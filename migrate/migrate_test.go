@@ -0,0 +1,262 @@
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aodin/aspect"
+	_ "github.com/aodin/aspect/sqlite3"
+)
+
+var coaches = aspect.Table("coaches",
+	aspect.Column("id", aspect.Integer{NotNull: true}),
+	aspect.PrimaryKey("id"),
+)
+
+func init() {
+	Register(20150103000000, "add_coaches",
+		Statements(coaches.Create()),
+		Statements(coaches.Drop()),
+	)
+}
+
+// connect opens a file-backed sqlite3 database, since each connection to
+// an in-memory database would receive its own database
+func connect(t *testing.T) (*aspect.DB, func()) {
+	dir, err := ioutil.TempDir("", "migrate")
+	require.Nil(t, err)
+	conn, err := aspect.Connect("sqlite3", filepath.Join(dir, "test.db"))
+	require.Nil(t, err)
+	return conn, func() {
+		conn.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestParseSQL(t *testing.T) {
+	up, down, err := ParseSQL(strings.NewReader(`-- +goose Up
+CREATE TABLE a (id INTEGER);
+INSERT INTO a (id)
+  VALUES (1);
+
+-- +goose Down
+DROP TABLE a;
+`))
+	require.Nil(t, err)
+	assert.Equal(t,
+		[]string{
+			"CREATE TABLE a (id INTEGER);",
+			"INSERT INTO a (id)\n  VALUES (1);",
+		},
+		up,
+	)
+	assert.Equal(t, []string{"DROP TABLE a;"}, down)
+
+	_, _, err = ParseSQL(strings.NewReader("CREATE TABLE a (id INTEGER);"))
+	assert.NotNil(t, err, "Migrations without an Up annotation should error")
+
+	_, _, err = ParseSQL(strings.NewReader("-- +goose Up\nCREATE TABLE a"))
+	assert.NotNil(t, err, "Unterminated statements should error")
+
+	// An unterminated StatementBegin should not spill into the Down
+	_, _, err = ParseSQL(strings.NewReader(`-- +goose Up
+-- +goose StatementBegin
+CREATE TRIGGER t AFTER INSERT ON a BEGIN SELECT 1; END;
+
+-- +goose Down
+DROP TRIGGER t;
+`))
+	assert.NotNil(t, err, "StatementBegin before a Down annotation should error")
+
+	_, _, err = ParseSQL(strings.NewReader(`-- +goose Up
+-- +goose StatementBegin
+CREATE TRIGGER t AFTER INSERT ON a BEGIN SELECT 1; END;
+`))
+	assert.NotNil(t, err, "StatementBegin without StatementEnd should error")
+
+	_, _, err = ParseSQL(strings.NewReader(`-- +goose Up
+CREATE TABLE a (id INTEGER)
+-- +goose Down
+DROP TABLE a;
+`))
+	assert.NotNil(t, err, "Unterminated statements before Down should error")
+
+	up, down, noTx, err := parseSQL(strings.NewReader(`-- +goose NO TRANSACTION
+-- +goose Up
+VACUUM;
+`))
+	require.Nil(t, err)
+	assert.True(t, noTx)
+	assert.Equal(t, []string{"VACUUM;"}, up)
+	assert.Equal(t, 0, len(down))
+}
+
+func TestMigrator(t *testing.T) {
+	conn, cleanup := connect(t)
+	defer cleanup()
+
+	m, err := New(conn, "./testdata")
+	require.Nil(t, err)
+	require.Equal(t, 3, len(m.Migrations()))
+	assert.Equal(t, "create_teams", m.Migrations()[0].Name)
+	assert.Equal(t, "", m.Migrations()[2].Source)
+
+	// The versions table is not created until a migration is run
+	statuses, err := m.Status()
+	require.Nil(t, err)
+	assert.False(t, statuses[0].Applied)
+	_, err = conn.Execute(Versions.Select())
+	assert.NotNil(t, err)
+
+	// Nothing can be rolled back before migrations are applied
+	assert.NotNil(t, m.Down())
+
+	require.Nil(t, m.Up())
+	version, err := m.Version()
+	require.Nil(t, err)
+	assert.Equal(t, int64(20150103000000), version)
+
+	statuses, err = m.Status()
+	require.Nil(t, err)
+	require.Equal(t, 3, len(statuses))
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.False(t, status.AppliedAt.IsZero())
+	}
+
+	// Tables and triggers should exist
+	conn.MustExecute(aspect.Raw(`INSERT INTO teams (id, name) VALUES (1, 'a')`))
+	conn.MustExecute(aspect.Raw(`INSERT INTO players (id, team_id) VALUES (1, 1)`))
+	conn.MustExecute(coaches.Insert().Values(aspect.Values{"id": 1}))
+
+	require.Nil(t, m.Redo())
+	require.Nil(t, m.Down())
+	version, err = m.Version()
+	require.Nil(t, err)
+	assert.Equal(t, int64(20150102000000), version)
+
+	// Roll back all migrations then apply up to a version
+	require.Nil(t, m.To(0))
+	version, err = m.Version()
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), version)
+
+	require.Nil(t, m.To(20150101000000))
+	statuses, err = m.Status()
+	require.Nil(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+	assert.NotNil(t, m.To(1), "Unknown versions should error")
+}
+
+func TestMigrator_Rollback(t *testing.T) {
+	conn, cleanup := connect(t)
+	defer cleanup()
+
+	m, err := NewWith(conn,
+		Migration{
+			Version: 1,
+			Name:    "broken",
+			Up: Statements(
+				coaches.Create(),
+				aspect.Raw(`INSERT INTO missing (id) VALUES (1)`),
+			),
+		},
+	)
+	require.Nil(t, err)
+
+	// A failed migration should not be recorded or partially applied
+	assert.NotNil(t, m.Up())
+	version, err := m.Version()
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), version)
+	_, err = conn.Execute(coaches.Select())
+	assert.NotNil(t, err)
+
+	_, err = NewWith(conn, Migration{Version: 1}, Migration{Version: 1})
+	assert.NotNil(t, err, "Duplicate versions should error")
+}
+
+func TestMigrator_Goose(t *testing.T) {
+	conn, cleanup := connect(t)
+	defer cleanup()
+
+	// Create the versions table as goose would, with the first migration
+	// applied and a later one applied then rolled back
+	conn.MustExecute(aspect.Raw(createVersions["sqlite3"]))
+	conn.MustExecute(aspect.Raw(`CREATE TABLE teams (id INTEGER PRIMARY KEY, name TEXT)`))
+	conn.MustExecute(aspect.Raw(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, 1)`))
+	conn.MustExecute(aspect.Raw(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (20150101000000, 1)`))
+	conn.MustExecute(aspect.Raw(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (20150102000000, 1)`))
+	conn.MustExecute(aspect.Raw(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (20150102000000, 0)`))
+
+	m, err := New(conn, "./testdata")
+	require.Nil(t, err)
+	version, err := m.Version()
+	require.Nil(t, err)
+	assert.Equal(t, int64(20150101000000), version)
+
+	// Only the remaining migrations should be run
+	require.Nil(t, m.Up())
+	statuses, err := m.Status()
+	require.Nil(t, err)
+	require.Equal(t, 3, len(statuses))
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.False(t, status.AppliedAt.IsZero())
+	}
+
+	// As in goose, rolling back inserts a row that is not applied
+	require.Nil(t, m.Down())
+	var applied []bool
+	conn.MustQueryAll(
+		aspect.Select(Versions.C["is_applied"]).Where(
+			Versions.C["version_id"].Equals(20150103000000),
+		).OrderBy(Versions.C["id"]),
+		&applied,
+	)
+	assert.Equal(t, []bool{true, false}, applied)
+
+	// The version zero row should not be duplicated
+	m, err = New(conn, "./testdata")
+	require.Nil(t, err)
+	require.Nil(t, m.Up())
+	var zeros []int64
+	conn.MustQueryAll(
+		aspect.Select(Versions.C["id"]).Where(Versions.C["version_id"].Equals(0)),
+		&zeros,
+	)
+	assert.Equal(t, 1, len(zeros))
+}
+
+func TestMigrator_NoTransaction(t *testing.T) {
+	conn, cleanup := connect(t)
+	defer cleanup()
+
+	// sqlite cannot VACUUM within a transaction
+	m, err := NewWith(conn,
+		Migration{
+			Version:       1,
+			Name:          "vacuum",
+			Up:            Statements(aspect.Raw(`VACUUM`)),
+			NoTransaction: true,
+		},
+	)
+	require.Nil(t, err)
+	require.Nil(t, m.Up())
+	version, err := m.Version()
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), version)
+
+	m, err = NewWith(conn,
+		Migration{Version: 2, Up: Statements(aspect.Raw(`VACUUM`))},
+	)
+	require.Nil(t, err)
+	assert.NotNil(t, m.Up(), "VACUUM should fail within a transaction")
+}
@@ -0,0 +1,50 @@
+package migrate
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aodin/aspect"
+)
+
+// Func is a single direction of a migration. It is run inside of the
+// migration's transaction.
+type Func func(tx aspect.Transaction) error
+
+// Registry of migrations declared in Go
+var registry = make(map[int64]Migration)
+
+// Register adds a migration declared in Go to the registry. Registered
+// migrations are run alongside the SQL migrations of any directory. Either
+// function may be nil if no statements are required.
+func Register(version int64, name string, up, down Func) {
+	if version < 1 {
+		log.Panic("migrate: migration versions must be positive")
+	}
+	if _, duplicate := registry[version]; duplicate {
+		log.Panicf("migrate: a migration with version %d already exists", version)
+	}
+	registry[version] = Migration{
+		Version: version,
+		Name:    name,
+		Up:      up,
+		Down:    down,
+	}
+}
+
+// Statements creates a Func that executes the given aspect statements, such
+// as those returned by aspect.Diff, in order.
+func Statements(stmts ...aspect.Executable) Func {
+	return func(tx aspect.Transaction) error {
+		for _, stmt := range stmts {
+			if _, err := tx.Execute(stmt); err != nil {
+				return fmt.Errorf(
+					"migrate: failed to execute (%s): %s",
+					tx.String(stmt),
+					err,
+				)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE players (
+  id INTEGER PRIMARY KEY,
+  team_id INTEGER REFERENCES teams(id)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER players_cleanup AFTER DELETE ON teams
+BEGIN
+  DELETE FROM players WHERE team_id = OLD.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER players_cleanup;
+DROP TABLE players;
@@ -1,7 +1,10 @@
 package mysql
 
 import (
-	_ "github.com/go-sql-driver/mysql"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/aodin/aspect"
 )
@@ -9,11 +12,108 @@ import (
 // MySQL implements the Dialect interface for MySQL databases.
 type MySQL struct{}
 
+// Name returns the name the dialect is registered with.
+func (d *MySQL) Name() string {
+	return "mysql"
+}
+
 // Parameterize returns the MySQL specific parameterization scheme.
 func (d *MySQL) Parameterize(i int) string {
 	return `?`
 }
 
+// EscapeString escapes backslashes in string literals, since MySQL treats
+// them as escape characters by default.
+func (d *MySQL) EscapeString(s string) string {
+	return strings.Replace(s, `\`, `\\`, -1)
+}
+
+// CanAlterColumn returns true only for DEFAULT, since MySQL changes the
+// type and NOT NULL constraint of a column with MODIFY COLUMN
+func (d *MySQL) CanAlterColumn(alteration aspect.ColumnAlteration) bool {
+	return alteration == aspect.AlterDefault
+}
+
+// Retryable returns true for deadlocks (error 1213)
+func (d *MySQL) Retryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == 1213
+}
+
+// TranslateError translates duplicate entries (error 1062), foreign key
+// failures (errors 1451 and 1452), NULL columns (error 1048), check
+// constraints (error 3819) and deadlocks (error 1213) into the typed errors
+// of aspect. Names are parsed from the error message.
+func (d *MySQL) TranslateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+	msg := mysqlErr.Message
+	violation := aspect.ConstraintError{Err: err}
+
+	switch mysqlErr.Number {
+	case 1062:
+		// Duplicate entry 'admin' for key 'users.name'
+		if i := strings.LastIndex(msg, " for key "); i != -1 {
+			key := between(msg[i+len(" for key "):], "'", "'")
+			if j := strings.LastIndex(key, "."); j != -1 {
+				violation.Table, key = key[:j], key[j+1:]
+			}
+			violation.Constraint = key
+		}
+		return &aspect.UniqueViolation{ConstraintError: violation}
+	case 1451, 1452:
+		// ... a foreign key constraint fails (`db`.`posts`, CONSTRAINT
+		// `posts_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES ...
+		if table := between(msg, "(", ","); table != "" {
+			parts := strings.Split(table, ".")
+			violation.Table = strings.Trim(parts[len(parts)-1], "`")
+		}
+		violation.Constraint = between(msg, "CONSTRAINT `", "`")
+		if columns := between(msg, "FOREIGN KEY (", ")"); columns != "" {
+			for _, column := range strings.Split(columns, ",") {
+				violation.Columns = append(
+					violation.Columns, strings.Trim(strings.TrimSpace(column), "`"),
+				)
+			}
+		}
+		return &aspect.ForeignKeyViolation{ConstraintError: violation}
+	case 1048:
+		// Column 'name' cannot be null
+		if column := between(msg, "'", "'"); column != "" {
+			violation.Columns = []string{column}
+		}
+		return &aspect.NotNullViolation{ConstraintError: violation}
+	case 3819:
+		// Check constraint 'positive_age' is violated.
+		violation.Constraint = between(msg, "'", "'")
+		return &aspect.CheckViolation{ConstraintError: violation}
+	case 1213:
+		return &aspect.Deadlock{Err: err}
+	}
+	return err
+}
+
+// between returns the text between the first occurrence of the start and
+// the following occurrence of the end, or an empty string if either is
+// missing
+func between(s, start, end string) string {
+	i := strings.Index(s, start)
+	if i == -1 {
+		return ""
+	}
+	s = s[i+len(start):]
+	j := strings.Index(s, end)
+	if j == -1 {
+		return ""
+	}
+	return s[:j]
+}
+
 // Add the mysql dialect to the dialect registry
 func init() {
 	aspect.RegisterDialect("mysql", &MySQL{})
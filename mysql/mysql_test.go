@@ -1,5 +1,88 @@
 package mysql
 
-import "github.com/aodin/aspect"
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aodin/aspect"
+)
 
 var _ aspect.Dialect = &MySQL{}
+var _ aspect.Namer = &MySQL{}
+var _ aspect.Escaper = &MySQL{}
+var _ aspect.Retrier = &MySQL{}
+var _ aspect.ErrorTranslator = &MySQL{}
+var _ aspect.ColumnAlterer = &MySQL{}
+
+func TestMySQL(t *testing.T) {
+	expect := aspect.NewTester(t, &MySQL{})
+	expect.SQL(`'C:\\path''s'`, aspect.Literal(`C:\path's`))
+}
+
+func TestMySQL_CanAlterColumn(t *testing.T) {
+	d := &MySQL{}
+	assert.True(t, d.CanAlterColumn(aspect.AlterDefault))
+	assert.False(t, d.CanAlterColumn(aspect.AlterType))
+	assert.False(t, d.CanAlterColumn(aspect.AlterNotNull))
+}
+
+func TestMySQL_Retryable(t *testing.T) {
+	d := &MySQL{}
+	assert.True(t, d.Retryable(&mysql.MySQLError{Number: 1213}))
+	assert.False(t, d.Retryable(&mysql.MySQLError{Number: 1062}))
+}
+
+func TestMySQL_TranslateError(t *testing.T) {
+	d := &MySQL{}
+	err := d.TranslateError(&mysql.MySQLError{
+		Number:  1062,
+		Message: "Duplicate entry 'admin' for key 'users.name'",
+	})
+	unique, ok := err.(*aspect.UniqueViolation)
+	require.True(t, ok, "error should be a UniqueViolation, not %T", err)
+	assert.Equal(t, "name", unique.Constraint)
+	assert.Equal(t, "users", unique.Table)
+
+	// Messages without a key should not be misparsed
+	err = d.TranslateError(&mysql.MySQLError{
+		Number: 1062, Message: "Duplicate entry 'admin'",
+	})
+	unique, ok = err.(*aspect.UniqueViolation)
+	require.True(t, ok, "error should be a UniqueViolation, not %T", err)
+	assert.Equal(t, "", unique.Constraint)
+	assert.Equal(t, "", unique.Table)
+
+	err = d.TranslateError(&mysql.MySQLError{
+		Number: 1452,
+		Message: "Cannot add or update a child row: a foreign key constraint " +
+			"fails (`blog`.`posts`, CONSTRAINT `posts_ibfk_1` FOREIGN KEY " +
+			"(`user_id`) REFERENCES `users` (`id`))",
+	})
+	fk, ok := err.(*aspect.ForeignKeyViolation)
+	require.True(t, ok, "error should be a ForeignKeyViolation, not %T", err)
+	assert.Equal(t, "posts_ibfk_1", fk.Constraint)
+	assert.Equal(t, "posts", fk.Table)
+	assert.Equal(t, []string{"user_id"}, fk.Columns)
+
+	err = d.TranslateError(&mysql.MySQLError{
+		Number: 1048, Message: "Column 'name' cannot be null",
+	})
+	notNull, ok := err.(*aspect.NotNullViolation)
+	require.True(t, ok, "error should be a NotNullViolation, not %T", err)
+	assert.Equal(t, []string{"name"}, notNull.Columns)
+
+	err = d.TranslateError(&mysql.MySQLError{
+		Number: 3819, Message: "Check constraint 'positive_age' is violated.",
+	})
+	check, ok := err.(*aspect.CheckViolation)
+	require.True(t, ok, "error should be a CheckViolation, not %T", err)
+	assert.Equal(t, "positive_age", check.Constraint)
+
+	assert.IsType(t, &aspect.Deadlock{}, d.TranslateError(&mysql.MySQLError{Number: 1213}))
+
+	other := &mysql.MySQLError{Number: 1146}
+	assert.Equal(t, other, d.TranslateError(other))
+}
@@ -0,0 +1,188 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/aodin/aspect"
+)
+
+var _ aspect.Reflector = &MySQL{}
+
+const tableNamesSQL = `SELECT table_name FROM information_schema.tables
+WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'
+ORDER BY table_name`
+
+// Columns are aliased since MySQL 8 returns upper case names
+const columnsSQL = `SELECT column_name AS column_name, data_type AS data_type,
+  column_type AS column_type, is_nullable AS is_nullable,
+  column_default AS column_default,
+  character_maximum_length AS character_maximum_length, extra AS extra
+FROM information_schema.columns
+WHERE table_schema = DATABASE() AND table_name = ?
+ORDER BY ordinal_position`
+
+// Constraints are returned with one row per column in key order
+const constraintsSQL = `SELECT tc.constraint_name AS constraint_name,
+  tc.constraint_type AS constraint_type, kcu.column_name AS column_name,
+  COALESCE(kcu.referenced_table_name, '') AS referenced_table_name,
+  COALESCE(kcu.referenced_column_name, '') AS referenced_column_name,
+  COALESCE(rc.delete_rule, '') AS delete_rule,
+  COALESCE(rc.update_rule, '') AS update_rule
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+  ON kcu.constraint_schema = tc.constraint_schema
+  AND kcu.table_name = tc.table_name
+  AND kcu.constraint_name = tc.constraint_name
+LEFT JOIN information_schema.referential_constraints rc
+  ON rc.constraint_schema = tc.constraint_schema
+  AND rc.constraint_name = tc.constraint_name
+WHERE tc.table_schema = DATABASE() AND tc.table_name = ?
+  AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY')
+ORDER BY tc.constraint_type, tc.constraint_name, kcu.ordinal_position`
+
+type columnRow struct {
+	Name       string         `db:"column_name"`
+	DataType   string         `db:"data_type"`
+	ColumnType string         `db:"column_type"`
+	Nullable   string         `db:"is_nullable"`
+	Default    sql.NullString `db:"column_default"`
+	Length     sql.NullInt64  `db:"character_maximum_length"`
+	Extra      string         `db:"extra"`
+}
+
+type constraintRow struct {
+	Name      string `db:"constraint_name"`
+	Type      string `db:"constraint_type"`
+	Column    string `db:"column_name"`
+	RefTable  string `db:"referenced_table_name"`
+	RefColumn string `db:"referenced_column_name"`
+	OnDelete  string `db:"delete_rule"`
+	OnUpdate  string `db:"update_rule"`
+}
+
+// TableNames returns the names of all tables in the current database.
+func (d *MySQL) TableNames(conn aspect.Connection) ([]string, error) {
+	var names []string
+	err := query(conn, &names, tableNamesSQL)
+	return names, err
+}
+
+// ReflectTable describes the table with the given name in the current
+// database using information_schema.
+func (d *MySQL) ReflectTable(conn aspect.Connection, name string) (aspect.TableInfo, error) {
+	info := aspect.TableInfo{Name: name}
+
+	var columns []columnRow
+	if err := query(conn, &columns, columnsSQL, name); err != nil {
+		return info, err
+	}
+	for _, column := range columns {
+		info.Columns = append(info.Columns, aspect.ColumnInfo{
+			Name: column.Name,
+			Type: parseType(column),
+		})
+	}
+
+	var rows []constraintRow
+	if err := query(conn, &rows, constraintsSQL, name); err != nil {
+		return info, err
+	}
+
+	// Group the rows of each constraint while preserving their order
+	var names []string
+	constraints := make(map[string][]constraintRow)
+	for _, row := range rows {
+		if _, exists := constraints[row.Name]; !exists {
+			names = append(names, row.Name)
+		}
+		constraints[row.Name] = append(constraints[row.Name], row)
+	}
+
+	for _, name := range names {
+		constraint := constraints[name]
+		columns := make([]string, len(constraint))
+		for i, row := range constraint {
+			columns[i] = row.Column
+		}
+		switch constraint[0].Type {
+		case "PRIMARY KEY":
+			info.PrimaryKey = columns
+		case "UNIQUE":
+			info.Uniques = append(info.Uniques, columns)
+		case "FOREIGN KEY":
+			// Multi-column foreign keys are not supported
+			if len(constraint) != 1 {
+				continue
+			}
+			info.ForeignKeys = append(info.ForeignKeys, aspect.ForeignKeyInfo{
+				Column:    constraint[0].Column,
+				RefTable:  constraint[0].RefTable,
+				RefColumn: constraint[0].RefColumn,
+				OnDelete:  aspect.ParseAction(constraint[0].OnDelete),
+				OnUpdate:  aspect.ParseAction(constraint[0].OnUpdate),
+			})
+		}
+	}
+	return info, nil
+}
+
+// parseType converts the reported type of a column to an aspect type.
+// Unrecognized types are returned as an aspect.RawType.
+func parseType(column columnRow) aspect.Type {
+	notNull := column.Nullable == "NO"
+
+	var def interface{}
+	if column.Default.Valid {
+		// Only expression defaults are reported as SQL, other defaults are
+		// reported as unquoted values
+		if strings.Contains(column.Extra, "DEFAULT_GENERATED") || strings.HasPrefix(strings.ToUpper(column.Default.String), "CURRENT_TIMESTAMP") {
+			def = aspect.Raw(column.Default.String)
+		} else {
+			def = aspect.Literal(column.Default.String)
+		}
+	}
+
+	switch strings.ToLower(column.DataType) {
+	case "int", "integer", "smallint", "mediumint":
+		return aspect.Integer{
+			NotNull:       notNull,
+			Autoincrement: strings.Contains(column.Extra, "auto_increment"),
+			Default:       def,
+		}
+	case "bigint":
+		return aspect.BigInt{NotNull: notNull, Default: def}
+	case "tinyint":
+		// MySQL represents booleans as TINYINT(1)
+		if strings.ToLower(column.ColumnType) == "tinyint(1)" {
+			return aspect.Boolean{NotNull: notNull, Default: def}
+		}
+	case "varchar":
+		return aspect.String{
+			Length: int(column.Length.Int64), NotNull: notNull, Default: def,
+		}
+	case "text":
+		return aspect.Text{NotNull: notNull, Default: def}
+	case "datetime", "timestamp":
+		return aspect.Timestamp{NotNull: notNull, Default: def}
+	case "date":
+		return aspect.Date{NotNull: notNull, Default: def}
+	case "float":
+		return aspect.Real{NotNull: notNull, Default: def}
+	case "double":
+		return aspect.Double{NotNull: notNull, Default: def}
+	}
+	return aspect.RawType{
+		Name: strings.ToUpper(column.ColumnType), NotNull: notNull, Default: def,
+	}
+}
+
+// query executes the given raw SQL and scans all results into dest
+func query(conn aspect.Connection, dest interface{}, stmt string, args ...interface{}) error {
+	result, err := conn.Query(aspect.Raw(stmt), args...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	return result.All(dest)
+}
@@ -3,6 +3,8 @@ package postgis
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/aodin/aspect"
 	"github.com/aodin/aspect/postgres"
 )
@@ -36,3 +38,18 @@ func TestGeoJSON(t *testing.T) {
 	expect := aspect.NewTester(t, &postgres.PostGres{})
 	expect.SQL(`ST_AsGeoJSON("shapes"."area")`, AsGeoJSON(shapes.C["area"]))
 }
+
+func TestParseGeometry(t *testing.T) {
+	g, ok := ParseGeometry("geometry(Point,4326)")
+	assert.True(t, ok)
+	assert.Equal(t, Geometry{Point{}, 4326}, g)
+
+	g, ok = ParseGeometry("geometry(POLYGON)")
+	assert.True(t, ok)
+	assert.Equal(t, Geometry{Geom: Polygon{}}, g)
+
+	_, ok = ParseGeometry("geometry")
+	assert.False(t, ok)
+	_, ok = ParseGeometry("geometry(CircularString,4326)")
+	assert.False(t, ok)
+}
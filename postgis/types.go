@@ -2,6 +2,8 @@ package postgis
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/aodin/aspect"
 )
@@ -39,3 +41,33 @@ func (g Geometry) IsUnique() bool {
 func (g Geometry) Validate(i interface{}) (interface{}, error) {
 	return i, nil
 }
+
+// subtypes are the geometry subtypes with a matching Shape
+var subtypes = map[string]Shape{
+	"POINT":      Point{},
+	"MULTIPOINT": MultiPoint{},
+	"LINESTRING": Linestring{},
+	"POLYGON":    Polygon{},
+}
+
+// ParseGeometry parses a geometry type as output by postgres, such as
+// geometry(Point,4326). It returns false if the type is not a geometry or
+// its subtype has no matching Shape.
+func ParseGeometry(name string) (g Geometry, ok bool) {
+	name = strings.TrimSpace(name)
+	if !strings.HasPrefix(strings.ToLower(name), "geometry(") || !strings.HasSuffix(name, ")") {
+		return
+	}
+	args := strings.Split(name[len("geometry("):len(name)-1], ",")
+	if g.Geom, ok = subtypes[strings.ToUpper(strings.TrimSpace(args[0]))]; !ok {
+		return
+	}
+	if len(args) > 1 {
+		srid, err := strconv.Atoi(strings.TrimSpace(args[1]))
+		if err != nil {
+			return g, false
+		}
+		g.SRID = srid
+	}
+	return g, true
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aodin/aspect"
+)
+
+// CreateStmt expands aspect's CreateStmt with postgres specific options.
+type CreateStmt struct {
+	aspect.CreateStmt
+	unlogged  bool
+	temporary bool
+}
+
+// String outputs the parameter-less CREATE TABLE statement in the postgres
+// dialect.
+func (stmt CreateStmt) String() string {
+	compiled, _ := stmt.Compile(&PostGres{}, aspect.Params())
+	return compiled
+}
+
+// Compile outputs the CREATE TABLE statement using the given dialect and
+// parameters.
+func (stmt CreateStmt) Compile(d aspect.Dialect, params *aspect.Parameters) (string, error) {
+	if stmt.unlogged && stmt.temporary {
+		return "", fmt.Errorf(
+			"postgres: a table cannot be both TEMPORARY and UNLOGGED",
+		)
+	}
+	compiled, err := stmt.CreateStmt.Compile(d, params)
+	if err != nil {
+		return "", err
+	}
+	if stmt.unlogged {
+		compiled = strings.Replace(
+			compiled, "CREATE TABLE", "CREATE UNLOGGED TABLE", 1,
+		)
+	}
+	return compiled, nil
+}
+
+// As proxies to the inner CreateStmt's As method
+func (stmt CreateStmt) As(selection aspect.SelectStmt) CreateStmt {
+	stmt.CreateStmt = stmt.CreateStmt.As(selection)
+	return stmt
+}
+
+// IfNotExists proxies to the inner CreateStmt's IfNotExists method
+func (stmt CreateStmt) IfNotExists() CreateStmt {
+	stmt.CreateStmt = stmt.CreateStmt.IfNotExists()
+	return stmt
+}
+
+// Temporary proxies to the inner CreateStmt's Temporary method
+func (stmt CreateStmt) Temporary() CreateStmt {
+	stmt.CreateStmt = stmt.CreateStmt.Temporary()
+	stmt.temporary = true
+	return stmt
+}
+
+// Unlogged creates the table as an UNLOGGED table. Data written to unlogged
+// tables is not written to the write-ahead log, which makes them faster
+// but not crash-safe.
+func (stmt CreateStmt) Unlogged() CreateStmt {
+	stmt.unlogged = true
+	return stmt
+}
+
+// Create creates a CREATE TABLE statement for the given table with postgres
+// specific options.
+func Create(table *aspect.TableElem) CreateStmt {
+	return CreateStmt{CreateStmt: table.Create()}
+}
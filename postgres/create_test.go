@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/aodin/aspect"
+)
+
+var rooms = aspect.Table("rooms",
+	aspect.Column("room", aspect.Integer{}),
+)
+
+func TestCreate(t *testing.T) {
+	expect := aspect.NewTester(t, &PostGres{})
+
+	expect.SQL(
+		`CREATE UNLOGGED TABLE IF NOT EXISTS "times" (
+  "room" INTEGER,
+  "when" daterange
+);`,
+		Create(times).Unlogged().IfNotExists(),
+	)
+	expect.SQL(
+		`CREATE UNLOGGED TABLE "rooms" AS SELECT "times"."room" FROM "times" WHERE "times"."room" = $1`,
+		Create(rooms).Unlogged().As(
+			aspect.Select(times.C["room"]).Where(times.C["room"].Equals(1)),
+		),
+		1,
+	)
+
+	// Temporary tables cannot be unlogged
+	expect.Error(Create(times).Unlogged().Temporary())
+}
@@ -11,9 +11,11 @@ type Inet struct {
 	NotNull    bool
 	Unique     bool
 	PrimaryKey bool
+	Default    interface{} // A literal value or a Clause
 }
 
 var _ aspect.Type = Inet{}
+var _ aspect.Defaulter = Inet{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s Inet) Create(d aspect.Dialect) (string, error) {
@@ -28,18 +30,30 @@ func (s Inet) Create(d aspect.Dialect) (string, error) {
 	if s.Unique {
 		attrs = append(attrs, "UNIQUE")
 	}
+	if aspect.HasDefaultValue(s.Default) {
+		def, err := aspect.CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		attrs = append(attrs, def)
+	}
 	if len(attrs) > 0 {
 		compiled += fmt.Sprintf(" %s", strings.Join(attrs, " "))
 	}
 	return compiled, nil
 }
 
+// HasDefault implements the aspect.Defaulter interface.
+func (s Inet) HasDefault() bool {
+	return aspect.HasDefaultValue(s.Default)
+}
+
 func (s Inet) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s Inet) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s Inet) IsUnique() bool {
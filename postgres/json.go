@@ -10,10 +10,11 @@ import (
 type JSON struct {
 	PrimaryKey bool
 	NotNull    bool
-	Default    string // TODO clause that compiles?
+	Default    interface{} // A literal value or a Clause
 }
 
 var _ aspect.Type = JSON{}
+var _ aspect.Defaulter = JSON{}
 
 func (s JSON) Create(d aspect.Dialect) (string, error) {
 	compiled := "JSON"
@@ -24,18 +25,30 @@ func (s JSON) Create(d aspect.Dialect) (string, error) {
 	if s.NotNull {
 		attrs = append(attrs, "NOT NULL")
 	}
+	if aspect.HasDefaultValue(s.Default) {
+		def, err := aspect.CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		attrs = append(attrs, def)
+	}
 	if len(attrs) > 0 {
 		compiled += fmt.Sprintf(" %s", strings.Join(attrs, " "))
 	}
 	return compiled, nil
 }
 
+// HasDefault implements the aspect.Defaulter interface.
+func (s JSON) HasDefault() bool {
+	return aspect.HasDefaultValue(s.Default)
+}
+
 func (s JSON) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s JSON) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s JSON) IsUnique() bool {
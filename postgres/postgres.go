@@ -1,9 +1,11 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/aodin/aspect"
 )
@@ -11,15 +13,82 @@ import (
 // PostGres implements the Dialect interface for postgres databases.
 type PostGres struct{}
 
+// Name returns the name the dialect is registered with.
+func (d *PostGres) Name() string {
+	return "postgres"
+}
+
 // Parameterize returns the postgres specific parameterization scheme.
 func (d *PostGres) Parameterize(i int) string {
 	return fmt.Sprintf(`$%d`, i)
 }
 
+// Retryable returns true for serialization failures (SQLSTATE 40001) and
+// deadlocks (SQLSTATE 40P01)
+func (d *PostGres) Retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+// TranslateError translates integrity constraint violations (SQLSTATE class
+// 23), serialization failures and deadlocks into the typed errors of aspect
+func (d *PostGres) TranslateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	violation := aspect.ConstraintError{
+		Constraint: pqErr.Constraint,
+		Table:      pqErr.Table,
+		Err:        err,
+	}
+	if pqErr.Column != "" {
+		violation.Columns = []string{pqErr.Column}
+	} else {
+		violation.Columns = detailColumns(pqErr.Detail)
+	}
+
+	switch pqErr.Code {
+	case "23505":
+		return &aspect.UniqueViolation{ConstraintError: violation}
+	case "23503":
+		return &aspect.ForeignKeyViolation{ConstraintError: violation}
+	case "23502":
+		return &aspect.NotNullViolation{ConstraintError: violation}
+	case "23514":
+		return &aspect.CheckViolation{ConstraintError: violation}
+	case "40001":
+		return &aspect.SerializationFailure{Err: err}
+	case "40P01":
+		return &aspect.Deadlock{Err: err}
+	}
+	return err
+}
+
+// detailColumns parses the column names from the detail of a violation,
+// such as: Key (email)=(admin@example.com) already exists.
+func detailColumns(detail string) []string {
+	if !strings.HasPrefix(detail, "Key (") {
+		return nil
+	}
+	end := strings.Index(detail, ")=(")
+	if end == -1 {
+		return nil
+	}
+	columns := strings.Split(detail[len("Key ("):end], ",")
+	for i, column := range columns {
+		columns[i] = strings.Trim(strings.TrimSpace(column), `"`)
+	}
+	return columns
+}
+
 // Add the postgres dialect to the dialect registry
 func init() {
 	aspect.RegisterDialect("postgres", &PostGres{})
 }
 
 // Now represents the clause needed to return a now timestamp in postgres
-var Now string = `now() at time zone 'utc'`
+var Now = aspect.Raw(`now() at time zone 'utc'`)
@@ -1,8 +1,11 @@
 package postgres
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/aodin/aspect"
@@ -10,6 +13,8 @@ import (
 )
 
 var _ aspect.Dialect = &PostGres{}
+var _ aspect.Namer = &PostGres{}
+var _ aspect.ErrorTranslator = &PostGres{}
 
 func TestPostGres(t *testing.T) {
 	// Connect to the database specified in the test db.json config
@@ -49,3 +54,50 @@ func TestPostGres(t *testing.T) {
 		tx.Rollback()
 	}
 }
+
+func TestPostGres_Retryable(t *testing.T) {
+	d := &PostGres{}
+	assert.True(t, d.Retryable(&pq.Error{Code: "40001"}))
+	assert.True(t, d.Retryable(fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"})))
+	assert.False(t, d.Retryable(&pq.Error{Code: "23505"}))
+	assert.False(t, d.Retryable(fmt.Errorf("40001")))
+}
+
+func TestPostGres_TranslateError(t *testing.T) {
+	d := &PostGres{}
+	err := d.TranslateError(&pq.Error{
+		Code:       "23505",
+		Constraint: "users_email_key",
+		Table:      "users",
+		Detail:     "Key (email)=(admin@example.com) already exists.",
+	})
+	unique, ok := err.(*aspect.UniqueViolation)
+	require.True(t, ok, "error should be a UniqueViolation, not %T", err)
+	assert.Equal(t, "users_email_key", unique.Constraint)
+	assert.Equal(t, "users", unique.Table)
+	assert.Equal(t, []string{"email"}, unique.Columns)
+
+	err = d.TranslateError(&pq.Error{
+		Code:   "23503",
+		Table:  "posts",
+		Detail: `Key (user_id, "group")=(1, 2) is not present in table "users".`,
+	})
+	fk, ok := err.(*aspect.ForeignKeyViolation)
+	require.True(t, ok, "error should be a ForeignKeyViolation, not %T", err)
+	assert.Equal(t, []string{"user_id", "group"}, fk.Columns)
+
+	err = d.TranslateError(&pq.Error{Code: "23502", Column: "name"})
+	notNull, ok := err.(*aspect.NotNullViolation)
+	require.True(t, ok, "error should be a NotNullViolation, not %T", err)
+	assert.Equal(t, []string{"name"}, notNull.Columns)
+
+	assert.IsType(t, &aspect.CheckViolation{}, d.TranslateError(&pq.Error{Code: "23514"}))
+	assert.IsType(t, &aspect.SerializationFailure{}, d.TranslateError(&pq.Error{Code: "40001"}))
+	assert.IsType(t, &aspect.Deadlock{}, d.TranslateError(&pq.Error{Code: "40P01"}))
+
+	// Translated errors remain retryable
+	assert.True(t, d.Retryable(d.TranslateError(&pq.Error{Code: "40001"})))
+
+	other := &pq.Error{Code: "42P01"}
+	assert.Equal(t, other, d.TranslateError(other))
+}
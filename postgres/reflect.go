@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/aodin/aspect"
+)
+
+var _ aspect.Reflector = &PostGres{}
+
+// Tables outside of the current schema are qualified with their schema
+const tableNamesSQL = `SELECT CASE WHEN table_schema = current_schema() THEN table_name ELSE table_schema || '.' || table_name END
+FROM information_schema.tables
+WHERE table_type = 'BASE TABLE' AND table_schema NOT IN ('pg_catalog', 'information_schema')
+ORDER BY table_schema, table_name`
+
+// The formatted type includes modifiers, such as those of postgis types
+const columnsSQL = `SELECT column_name, data_type, is_nullable, column_default, character_maximum_length,
+  (SELECT format_type(att.atttypid, att.atttypmod) FROM pg_attribute att
+    WHERE att.attrelid = (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass
+    AND att.attname = column_name) AS formatted_type
+FROM information_schema.columns
+WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+ORDER BY ordinal_position`
+
+// Constraint columns are returned as comma separated lists in key order
+const constraintsSQL = `SELECT con.contype AS type,
+  array_to_string(ARRAY(
+    SELECT att.attname FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+    JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = k.attnum
+    ORDER BY k.ord
+  ), ',') AS columns,
+  COALESCE(CASE WHEN rns.nspname = current_schema() THEN rcls.relname ELSE rns.nspname || '.' || rcls.relname END, '') AS ref_table,
+  array_to_string(ARRAY(
+    SELECT att.attname FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+    JOIN pg_attribute att ON att.attrelid = con.confrelid AND att.attnum = k.attnum
+    ORDER BY k.ord
+  ), ',') AS ref_columns,
+  con.confdeltype AS on_delete,
+  con.confupdtype AS on_update
+FROM pg_constraint con
+JOIN pg_class cls ON cls.oid = con.conrelid
+JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+LEFT JOIN pg_class rcls ON rcls.oid = con.confrelid
+LEFT JOIN pg_namespace rns ON rns.oid = rcls.relnamespace
+WHERE ns.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND cls.relname = $2 AND con.contype IN ('p', 'u', 'f')
+ORDER BY con.contype, con.conname`
+
+type columnRow struct {
+	Name     string         `db:"column_name"`
+	DataType string         `db:"data_type"`
+	Nullable string         `db:"is_nullable"`
+	Default  sql.NullString `db:"column_default"`
+	Length   sql.NullInt64  `db:"character_maximum_length"`
+	Format   string         `db:"formatted_type"`
+}
+
+type constraintRow struct {
+	Type       string `db:"type"`
+	Columns    string `db:"columns"`
+	RefTable   string `db:"ref_table"`
+	RefColumns string `db:"ref_columns"`
+	OnDelete   string `db:"on_delete"`
+	OnUpdate   string `db:"on_update"`
+}
+
+// actions maps the action codes of pg_constraint to foreign key actions
+var actions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// TableNames returns the names of all tables in the database. Tables
+// outside of the current schema will be qualified, such as audit.logs.
+func (d *PostGres) TableNames(conn aspect.Connection) ([]string, error) {
+	var names []string
+	err := query(conn, &names, tableNamesSQL)
+	return names, err
+}
+
+// ReflectTable describes the table with the given name, which may be
+// schema qualified, using information_schema and pg_catalog.
+func (d *PostGres) ReflectTable(conn aspect.Connection, name string) (aspect.TableInfo, error) {
+	info := aspect.TableInfo{Name: name}
+	if i := strings.Index(name, "."); i != -1 {
+		info.Schema, info.Name = name[:i], name[i+1:]
+	}
+
+	var columns []columnRow
+	if err := query(conn, &columns, columnsSQL, info.Schema, info.Name); err != nil {
+		return info, err
+	}
+	for _, column := range columns {
+		info.Columns = append(info.Columns, aspect.ColumnInfo{
+			Name: column.Name,
+			Type: parseType(column),
+		})
+	}
+
+	var constraints []constraintRow
+	if err := query(conn, &constraints, constraintsSQL, info.Schema, info.Name); err != nil {
+		return info, err
+	}
+	for _, constraint := range constraints {
+		names := strings.Split(constraint.Columns, ",")
+		switch constraint.Type {
+		case "p":
+			info.PrimaryKey = names
+		case "u":
+			info.Uniques = append(info.Uniques, names)
+		case "f":
+			// Multi-column foreign keys are not supported
+			if len(names) != 1 {
+				continue
+			}
+			info.ForeignKeys = append(info.ForeignKeys, aspect.ForeignKeyInfo{
+				Column:    names[0],
+				RefTable:  constraint.RefTable,
+				RefColumn: constraint.RefColumns,
+				OnDelete:  aspect.ParseAction(actions[constraint.OnDelete]),
+				OnUpdate:  aspect.ParseAction(actions[constraint.OnUpdate]),
+			})
+		}
+	}
+	return info, nil
+}
+
+// parseType converts the reported type of a column to an aspect type.
+// Unrecognized types are returned as an aspect.RawType.
+func parseType(column columnRow) aspect.Type {
+	notNull := column.Nullable == "NO"
+
+	var def interface{}
+	if column.Default.Valid {
+		// Integer columns with a sequence default are serials
+		if column.DataType == "integer" && strings.HasPrefix(column.Default.String, "nextval(") {
+			return Serial{NotNull: notNull}
+		}
+		def = aspect.Raw(column.Default.String)
+	}
+
+	switch column.DataType {
+	case "integer", "smallint":
+		return aspect.Integer{NotNull: notNull, Default: def}
+	case "bigint":
+		return aspect.BigInt{NotNull: notNull, Default: def}
+	case "character varying":
+		return aspect.String{
+			Length: int(column.Length.Int64), NotNull: notNull, Default: def,
+		}
+	case "text":
+		return aspect.Text{NotNull: notNull, Default: def}
+	case "boolean":
+		return aspect.Boolean{NotNull: notNull, Default: def}
+	case "timestamp without time zone":
+		return aspect.Timestamp{NotNull: notNull, Default: def}
+	case "timestamp with time zone":
+		return aspect.Timestamp{
+			WithTimezone: true, NotNull: notNull, Default: def,
+		}
+	case "date":
+		return aspect.Date{NotNull: notNull, Default: def}
+	case "real":
+		return aspect.Real{NotNull: notNull, Default: def}
+	case "double precision":
+		return aspect.Double{NotNull: notNull, Default: def}
+	case "uuid":
+		return UUID{NotNull: notNull, Default: def}
+	case "json":
+		return JSON{NotNull: notNull, Default: def}
+	case "inet":
+		return Inet{NotNull: notNull, Default: def}
+	case "daterange":
+		return DateRange{}
+	}
+
+	// Other types are output with their modifiers, such as numeric(10,2),
+	// integer[] and geometry(Point,4326)
+	name := strings.ToUpper(column.DataType)
+	if column.Format != "" {
+		name = column.Format
+	}
+	return aspect.RawType{Name: name, NotNull: notNull, Default: def}
+}
+
+// query executes the given raw SQL and scans all results into dest
+func query(conn aspect.Connection, dest interface{}, stmt string, args ...interface{}) error {
+	result, err := conn.Query(aspect.Raw(stmt), args...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	return result.All(dest)
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/aodin/aspect"
+)
+
+// CreateSchemaStmt is the internal representation of a CREATE SCHEMA
+// statement.
+type CreateSchemaStmt struct {
+	name          string
+	ifNotExists   bool
+	authorization string
+}
+
+// Authorization sets the role that will own the schema.
+func (stmt CreateSchemaStmt) Authorization(role string) CreateSchemaStmt {
+	stmt.authorization = role
+	return stmt
+}
+
+// IfNotExists adds the IF NOT EXISTS modifier to the CREATE SCHEMA statement.
+func (stmt CreateSchemaStmt) IfNotExists() CreateSchemaStmt {
+	stmt.ifNotExists = true
+	return stmt
+}
+
+// Compile outputs the CREATE SCHEMA statement using the given dialect and
+// parameters.
+func (stmt CreateSchemaStmt) Compile(d aspect.Dialect, ps *aspect.Parameters) (string, error) {
+	if stmt.name == "" {
+		return "", fmt.Errorf("postgres: schema names cannot be blank")
+	}
+	compiled := "CREATE SCHEMA"
+	if stmt.ifNotExists {
+		compiled += " IF NOT EXISTS"
+	}
+	compiled += fmt.Sprintf(` "%s"`, stmt.name)
+	if stmt.authorization != "" {
+		compiled += fmt.Sprintf(` AUTHORIZATION "%s"`, stmt.authorization)
+	}
+	return compiled, nil
+}
+
+// String outputs the parameter-less CREATE SCHEMA statement.
+func (stmt CreateSchemaStmt) String() string {
+	output, _ := stmt.Compile(&PostGres{}, aspect.Params())
+	return output
+}
+
+// CreateSchema creates a CREATE SCHEMA statement for the given name.
+func CreateSchema(name string) (stmt CreateSchemaStmt) {
+	stmt.name = name
+	return
+}
+
+// DropSchemaStmt is the internal representation of a DROP SCHEMA statement.
+type DropSchemaStmt struct {
+	name     string
+	ifExists bool
+	cascade  bool
+}
+
+// Cascade adds the CASCADE modifier to the DROP SCHEMA statement, which
+// will also drop all objects contained in the schema.
+func (stmt DropSchemaStmt) Cascade() DropSchemaStmt {
+	stmt.cascade = true
+	return stmt
+}
+
+// IfExists adds the IF EXISTS modifier to the DROP SCHEMA statement.
+func (stmt DropSchemaStmt) IfExists() DropSchemaStmt {
+	stmt.ifExists = true
+	return stmt
+}
+
+// Compile outputs the DROP SCHEMA statement using the given dialect and
+// parameters.
+func (stmt DropSchemaStmt) Compile(d aspect.Dialect, ps *aspect.Parameters) (string, error) {
+	if stmt.name == "" {
+		return "", fmt.Errorf("postgres: schema names cannot be blank")
+	}
+	compiled := "DROP SCHEMA"
+	if stmt.ifExists {
+		compiled += " IF EXISTS"
+	}
+	compiled += fmt.Sprintf(` "%s"`, stmt.name)
+	if stmt.cascade {
+		compiled += " CASCADE"
+	}
+	return compiled, nil
+}
+
+// String outputs the parameter-less DROP SCHEMA statement.
+func (stmt DropSchemaStmt) String() string {
+	output, _ := stmt.Compile(&PostGres{}, aspect.Params())
+	return output
+}
+
+// DropSchema creates a DROP SCHEMA statement for the given name.
+func DropSchema(name string) (stmt DropSchemaStmt) {
+	stmt.name = name
+	return
+}
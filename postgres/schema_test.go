@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/aodin/aspect"
+)
+
+var auditLogs = aspect.Table("logs",
+	aspect.Schema("audit"),
+	aspect.Column("id", Serial{PrimaryKey: true}),
+	aspect.Column("message", aspect.Text{}),
+)
+
+func TestSchema(t *testing.T) {
+	expect := aspect.NewTester(t, &PostGres{})
+
+	expect.Error(CreateSchema(""))
+	expect.SQL(`CREATE SCHEMA "audit"`, CreateSchema("audit"))
+	expect.SQL(
+		`CREATE SCHEMA IF NOT EXISTS "audit" AUTHORIZATION "admin"`,
+		CreateSchema("audit").IfNotExists().Authorization("admin"),
+	)
+
+	expect.Error(DropSchema(""))
+	expect.SQL(`DROP SCHEMA "audit"`, DropSchema("audit"))
+	expect.SQL(
+		`DROP SCHEMA IF EXISTS "audit" CASCADE`,
+		DropSchema("audit").IfExists().Cascade(),
+	)
+
+	// Tables in a schema should use their qualified name
+	expect.SQL(
+		`INSERT INTO "audit"."logs" ("message") VALUES ($1) RETURNING "audit"."logs"."id"`,
+		Insert(auditLogs.C["message"]).Values(
+			aspect.Values{"message": "hello"},
+		).Returning(auditLogs.C["id"]),
+		"hello",
+	)
+}
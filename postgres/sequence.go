@@ -50,6 +50,15 @@ func (stmt AlterSeqStmt) String() string {
 	return output
 }
 
+// NextVal returns a clause that advances the given sequence and returns its
+// new value. It can be used as a column DEFAULT.
+func NextVal(sequence Sequence) aspect.Clause {
+	return aspect.FuncClause{
+		Inner: aspect.Literal(string(sequence)),
+		F:     "nextval",
+	}
+}
+
 func AlterSequence(sequence Sequence) (stmt AlterSeqStmt) {
 	stmt.sequence = sequence
 	return
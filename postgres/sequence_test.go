@@ -19,4 +19,10 @@ func TestSequence(t *testing.T) {
 		`ALTER SEQUENCE "companies_id_seq" RESTART WITH 1`,
 		AlterSequence(Sequence("companies_id_seq")).RestartWith(1),
 	)
+
+	expect.SQL(`nextval('companies_id_seq')`, NextVal("companies_id_seq"))
+	expect.Create(
+		`BIGINT NOT NULL DEFAULT (nextval('companies_id_seq'))`,
+		aspect.BigInt{NotNull: true, Default: NextVal("companies_id_seq")},
+	)
 }
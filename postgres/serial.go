@@ -14,6 +14,7 @@ type Serial struct {
 }
 
 var _ aspect.Type = Serial{}
+var _ aspect.Defaulter = Serial{}
 
 func (s Serial) Create(d aspect.Dialect) (string, error) {
 	compiled := "SERIAL"
@@ -30,12 +31,18 @@ func (s Serial) Create(d aspect.Dialect) (string, error) {
 	return compiled, nil
 }
 
+// HasDefault implements the aspect.Defaulter interface. Serial columns
+// always default to the next value of their sequence.
+func (s Serial) HasDefault() bool {
+	return true
+}
+
 func (s Serial) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s Serial) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s Serial) IsUnique() bool {
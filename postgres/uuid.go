@@ -7,15 +7,18 @@ import (
 	"github.com/aodin/aspect"
 )
 
-var GenerateV4 string = `uuid_generate_v4()`
+// GenerateV4 is a clause that generates a random UUID. It requires the
+// uuid-ossp extension.
+var GenerateV4 = aspect.Raw(`uuid_generate_v4()`)
 
 type UUID struct {
 	PrimaryKey bool
 	NotNull    bool
-	Default    string // TODO clause that compiles?
+	Default    interface{} // A literal value or a Clause such as GenerateV4
 }
 
 var _ aspect.Type = UUID{}
+var _ aspect.Defaulter = UUID{}
 
 func (s UUID) Create(d aspect.Dialect) (string, error) {
 	compiled := "UUID"
@@ -26,8 +29,12 @@ func (s UUID) Create(d aspect.Dialect) (string, error) {
 	if s.NotNull {
 		attrs = append(attrs, "NOT NULL")
 	}
-	if s.Default != "" {
-		attrs = append(attrs, fmt.Sprintf("DEFAULT %s", s.Default))
+	if aspect.HasDefaultValue(s.Default) {
+		def, err := aspect.CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		attrs = append(attrs, def)
 	}
 	if len(attrs) > 0 {
 		compiled += fmt.Sprintf(" %s", strings.Join(attrs, " "))
@@ -35,12 +42,17 @@ func (s UUID) Create(d aspect.Dialect) (string, error) {
 	return compiled, nil
 }
 
+// HasDefault implements the aspect.Defaulter interface.
+func (s UUID) HasDefault() bool {
+	return aspect.HasDefaultValue(s.Default)
+}
+
 func (s UUID) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s UUID) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s UUID) IsUnique() bool {
@@ -11,7 +11,7 @@ func TestUUID(t *testing.T) {
 	expect.Create("UUID", UUID{})
 	expect.Create("UUID PRIMARY KEY", UUID{PrimaryKey: true})
 	expect.Create(
-		"UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4()",
+		"UUID PRIMARY KEY NOT NULL DEFAULT (uuid_generate_v4())",
 		UUID{PrimaryKey: true, NotNull: true, Default: GenerateV4},
 	)
 }
@@ -0,0 +1,330 @@
+package aspect
+
+import (
+	"container/list"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// stmtCache is a least recently used cache of prepared statements keyed by
+// their compiled SQL. Statements are reference counted, so an evicted
+// statement is only closed once every caller using it has released it.
+// database/sql will also wait for any rows still open from it.
+type stmtCache struct {
+	conn  *sql.DB
+	size  int
+	mu    sync.Mutex
+	order *list.List // of *cachedStmt, most recently used first
+	stmts map[string]*list.Element
+}
+
+type cachedStmt struct {
+	sql     string
+	stmt    *sql.Stmt
+	refs    int  // the number of callers using the statement
+	evicted bool // the statement will be closed when refs reaches zero
+}
+
+func newStmtCache(conn *sql.DB, size int) *stmtCache {
+	return &stmtCache{
+		conn:  conn,
+		size:  size,
+		order: list.New(),
+		stmts: make(map[string]*list.Element),
+	}
+}
+
+// prepare returns the cached prepared statement of the given SQL, preparing
+// it if needed. The statement must be given to release once it is no
+// longer used.
+func (c *stmtCache) prepare(s string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if elem, ok := c.stmts[s]; ok {
+		c.order.MoveToFront(elem)
+		cached := elem.Value.(*cachedStmt)
+		cached.refs += 1
+		c.mu.Unlock()
+		return cached, nil
+	}
+	c.mu.Unlock()
+
+	// Prepare without holding the lock
+	stmt, err := c.conn.Prepare(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another caller may have prepared the same statement
+	if elem, ok := c.stmts[s]; ok {
+		stmt.Close()
+		c.order.MoveToFront(elem)
+		cached := elem.Value.(*cachedStmt)
+		cached.refs += 1
+		return cached, nil
+	}
+	cached := &cachedStmt{sql: s, stmt: stmt, refs: 1}
+	c.stmts[s] = c.order.PushFront(cached)
+	for c.order.Len() > c.size {
+		oldest := c.order.Remove(c.order.Back()).(*cachedStmt)
+		delete(c.stmts, oldest.sql)
+		c.evict(oldest)
+	}
+	return cached, nil
+}
+
+// release releases a statement returned by prepare, closing it if it has
+// been evicted and has no other users
+func (c *stmtCache) release(cached *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached.refs -= 1
+	if cached.evicted && cached.refs == 0 {
+		cached.stmt.Close()
+	}
+}
+
+// evict closes the statement, or marks it to be closed by its last user.
+// The lock must be held.
+func (c *stmtCache) evict(cached *cachedStmt) error {
+	cached.evicted = true
+	if cached.refs > 0 {
+		return nil
+	}
+	return cached.stmt.Close()
+}
+
+// Len returns the number of cached statements
+func (c *stmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// close closes and removes every cached statement. Statements that are
+// still in use are closed when they are released.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		if closeErr := c.evict(elem.Value.(*cachedStmt)); err == nil {
+			err = closeErr
+		}
+	}
+	c.order.Init()
+	c.stmts = make(map[string]*list.Element)
+	return err
+}
+
+// cachedExecer executes SQL with the prepared statements of a cache. If
+// given a transaction, the statements are rebound to it.
+type cachedExecer struct {
+	cache *stmtCache
+	tx    *sql.Tx
+}
+
+func (c cachedExecer) Exec(s string, args ...interface{}) (sql.Result, error) {
+	cached, err := c.cache.prepare(s)
+	if err != nil {
+		return nil, err
+	}
+	defer c.cache.release(cached)
+	stmt := cached.stmt
+	if c.tx != nil {
+		stmt = c.tx.Stmt(stmt)
+		defer stmt.Close()
+	}
+	return stmt.Exec(args...)
+}
+
+func (c cachedExecer) Query(s string, args ...interface{}) (*sql.Rows, error) {
+	cached, err := c.cache.prepare(s)
+	if err != nil {
+		return nil, err
+	}
+	// The statement may be released before the returned rows are closed,
+	// since database/sql waits for them before closing it
+	defer c.cache.release(cached)
+	stmt := cached.stmt
+	// Statements of transactions are closed with the transaction, since
+	// the returned rows are still open
+	if c.tx != nil {
+		stmt = c.tx.Stmt(stmt)
+	}
+	return stmt.Query(args...)
+}
+
+// preparedExecer executes a single prepared statement
+type preparedExecer struct {
+	stmt *sql.Stmt
+	sql  string
+}
+
+func (p preparedExecer) check(s string) error {
+	if s != p.sql {
+		return fmt.Errorf(
+			"aspect: the SQL of prepared statements cannot be rewritten",
+		)
+	}
+	return nil
+}
+
+func (p preparedExecer) Exec(s string, args ...interface{}) (sql.Result, error) {
+	if err := p.check(s); err != nil {
+		return nil, err
+	}
+	return p.stmt.Exec(args...)
+}
+
+func (p preparedExecer) Query(s string, args ...interface{}) (*sql.Rows, error) {
+	if err := p.check(s); err != nil {
+		return nil, err
+	}
+	return p.stmt.Query(args...)
+}
+
+// PreparedStmt is a statement that has been compiled and prepared once,
+// which can then be executed many times with new parameter values. It
+// keeps the options of the connection that prepared it.
+type PreparedStmt struct {
+	stmt       Executable
+	sql        string
+	args       []interface{}
+	prepared   *sql.Stmt
+	dialect    Dialect
+	strict     bool
+	converters Converters
+	middleware middleware
+	tx         bool
+}
+
+// Prepare compiles and prepares the given statement. Its compiled
+// parameters are used as defaults when it is executed without arguments:
+//
+//  byName, err := conn.Prepare(Users.Select().Where(Users.C["name"].Equals("")))
+//  if err != nil {
+//      return err
+//  }
+//  defer byName.Close()
+//
+//  var user User
+//  err = byName.QueryOne(&user, "admin")
+//
+func (db *DB) Prepare(stmt Executable) (*PreparedStmt, error) {
+	s, params, err := db.compile(stmt)
+	if err != nil {
+		return nil, err
+	}
+	prepared, err := db.conn.Prepare(s)
+	if err != nil {
+		return nil, err
+	}
+	return &PreparedStmt{
+		stmt:       stmt,
+		sql:        s,
+		args:       params.args,
+		prepared:   prepared,
+		dialect:    db.dialect,
+		strict:     db.strict,
+		converters: db.converters,
+		middleware: db.middleware,
+	}, nil
+}
+
+// WithStmtCache returns a copy of the database connection pool that will
+// prepare every statement it executes and cache up to the given number of
+// prepared statements by their compiled SQL. Transactions begun by the copy
+// rebind the cached statements to the transaction. The cache is closed
+// with the copy.
+func (db *DB) WithStmtCache(size int) *DB {
+	cached := *db
+	if size < 1 {
+		cached.cache = nil
+	} else {
+		cached.cache = newStmtCache(db.conn, size)
+	}
+	return &cached
+}
+
+// String returns the compiled SQL of the prepared statement
+func (p *PreparedStmt) String() string {
+	return p.sql
+}
+
+// In returns a copy of the prepared statement that will be executed within
+// the given transaction. It should not be used after the transaction ends.
+func (p *PreparedStmt) In(tx *TX) *PreparedStmt {
+	bound := *p
+	bound.prepared = tx.Tx.Stmt(p.prepared)
+	bound.middleware = tx.middleware
+	bound.tx = true
+	return &bound
+}
+
+// Close closes the prepared statement
+func (p *PreparedStmt) Close() error {
+	return p.prepared.Close()
+}
+
+// statement returns the statement given to the middleware with the given
+// arguments, or the compiled arguments if none or only Args are given
+func (p *PreparedStmt) statement(args []interface{}) (Statement, error) {
+	args, err := bindArgs(p.converters, p.args, args)
+	if err != nil {
+		return Statement{}, err
+	}
+	return Statement{
+		Stmt:          p.stmt,
+		SQL:           p.sql,
+		Args:          args,
+		Dialect:       p.dialect,
+		InTransaction: p.tx,
+	}, nil
+}
+
+// Execute executes the prepared statement with the given arguments
+func (p *PreparedStmt) Execute(args ...interface{}) (sql.Result, error) {
+	statement, err := p.statement(args)
+	if err != nil {
+		return nil, err
+	}
+	return p.middleware.exec(preparedExecer{p.prepared, p.sql}, statement)
+}
+
+// Query queries the prepared statement with the given arguments
+func (p *PreparedStmt) Query(args ...interface{}) (*Result, error) {
+	statement, err := p.statement(args)
+	if err != nil {
+		return nil, err
+	}
+	rows, s, err := p.middleware.query(preparedExecer{p.prepared, p.sql}, statement)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(rows, s, statement.Args, p.stmt, p.strict, p.converters), nil
+}
+
+// QueryAll queries the prepared statement with the given arguments and
+// populates the destination with all results
+func (p *PreparedStmt) QueryAll(dest interface{}, args ...interface{}) error {
+	result, err := p.Query(args...)
+	if err != nil {
+		return err
+	}
+	return result.All(dest)
+}
+
+// QueryOne queries the prepared statement with the given arguments and
+// populates the destination with a single result
+func (p *PreparedStmt) QueryOne(dest interface{}, args ...interface{}) error {
+	result, err := p.Query(args...)
+	if err != nil {
+		return err
+	}
+	defer result.rows.Close()
+	return result.One(dest)
+}
@@ -0,0 +1,226 @@
+package aspect
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingDriver counts the statements prepared and closed and the
+// transactions committed and rolled back by its connections, which return
+// no rows
+type countingDriver struct {
+	mu        sync.Mutex
+	prepared  []string
+	closed    int
+	commits   int
+	rollbacks int
+}
+
+func (d *countingDriver) Open(name string) (driver.Conn, error) {
+	return &countingConn{d}, nil
+}
+
+type countingConn struct {
+	driver *countingDriver
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.prepared = append(c.driver.prepared, query)
+	return &countingStmt{c.driver}, nil
+}
+
+func (c *countingConn) Close() error              { return nil }
+func (c *countingConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *countingConn) Commit() error {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.commits += 1
+	return nil
+}
+
+func (c *countingConn) Rollback() error {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.rollbacks += 1
+	return nil
+}
+
+type countingStmt struct {
+	driver *countingDriver
+}
+
+func (s *countingStmt) Close() error {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+	s.driver.closed += 1
+	return nil
+}
+
+func (s *countingStmt) NumInput() int { return -1 }
+
+func (s *countingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *countingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return countingRows{}, nil
+}
+
+type countingRows struct{}
+
+func (countingRows) Columns() []string              { return []string{"id"} }
+func (countingRows) Close() error                   { return nil }
+func (countingRows) Next(dest []driver.Value) error { return io.EOF }
+
+func openCounting(t *testing.T, name string) (*DB, *countingDriver) {
+	d := &countingDriver{}
+	sql.Register(name, d)
+	conn, err := sql.Open(name, "")
+	require.Nil(t, err)
+	return &DB{conn: conn, dialect: &defaultDialect{}}, d
+}
+
+func TestStmtCache(t *testing.T) {
+	db, d := openCounting(t, "counting_cache")
+	defer db.Close()
+	conn := db.WithStmtCache(2)
+
+	first := users.Delete().Where(users.C["id"].Equals(1))
+	second := users.Delete().Where(users.C["name"].Equals("admin"))
+	third := users.Delete()
+
+	// Statements are prepared once per compiled SQL
+	for i := 0; i < 3; i++ {
+		_, err := conn.Execute(first)
+		require.Nil(t, err)
+	}
+	assert.Equal(t, 1, len(d.prepared))
+	assert.Equal(t, 1, conn.cache.Len())
+
+	// The least recently used statement is evicted and closed
+	conn.MustExecute(second)
+	conn.MustExecute(first)
+	conn.MustExecute(third)
+	assert.Equal(t, 3, len(d.prepared))
+	assert.Equal(t, 2, conn.cache.Len())
+	assert.Equal(t, 1, d.closed)
+	conn.MustExecute(first)
+	assert.Equal(t, 3, len(d.prepared))
+
+	// Transactions rebind the cached statements
+	tx := conn.MustBegin()
+	tx.MustExecute(first)
+	var ids []int64
+	require.Nil(t, tx.QueryAll(users.Select(), &ids))
+	require.Nil(t, tx.Commit())
+	assert.Equal(t, 2, conn.cache.Len())
+
+	// The original connection does not cache
+	prepared := len(d.prepared)
+	db.MustExecute(first)
+	db.MustExecute(first)
+	assert.Equal(t, prepared+2, len(d.prepared))
+}
+
+func TestStmtCache_Concurrent(t *testing.T) {
+	db, d := openCounting(t, "counting_cache_concurrent")
+	defer db.Close()
+	conn := db.WithStmtCache(1)
+
+	// An evicted statement stays open until its user releases it
+	held, err := conn.cache.prepare("SELECT 1")
+	require.Nil(t, err)
+	other, err := conn.cache.prepare("SELECT 2")
+	require.Nil(t, err)
+	conn.cache.release(other)
+	_, err = held.stmt.Exec()
+	assert.Nil(t, err)
+	conn.cache.release(held)
+	_, err = held.stmt.Exec()
+	assert.NotNil(t, err)
+
+	// Every execution evicts the statement that others may be using
+	stmts := []Executable{
+		users.Delete().Where(users.C["id"].Equals(1)),
+		users.Delete().Where(users.C["name"].Equals("admin")),
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 32*100)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(stmt Executable) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := conn.Execute(stmt); err != nil {
+					errs <- err
+				}
+			}
+		}(stmts[i%2])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Every statement but the cached one has been closed
+	require.Nil(t, conn.cache.close())
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	assert.Equal(t, len(d.prepared), d.closed)
+}
+
+func TestPreparedStmt(t *testing.T) {
+	db, d := openCounting(t, "counting_prepare")
+	defer db.Close()
+
+	var events []Event
+	conn := db.WithHook(func(event Event) { events = append(events, event) })
+
+	stmt, err := conn.Prepare(users.Delete().Where(users.C["id"].Equals(1)))
+	require.Nil(t, err)
+	defer stmt.Close()
+	assert.Equal(t, `DELETE FROM "users" WHERE "users"."id" = $1`, stmt.String())
+
+	// Compiled parameters are used unless others are given
+	result, err := stmt.Execute()
+	require.Nil(t, err)
+	affected, _ := result.RowsAffected()
+	assert.EqualValues(t, 1, affected)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, []interface{}{1}, events[0].Args)
+
+	_, err = stmt.Execute(2)
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{2}, events[1].Args)
+
+	var ids []int64
+	require.Nil(t, stmt.QueryAll(&ids, 3))
+	assert.Equal(t, ErrNoResult, stmt.QueryOne(&ids, 3))
+	assert.Equal(t, 1, len(d.prepared))
+
+	// Prepared statements can be used within transactions
+	tx := conn.MustBegin().(*TX)
+	_, err = stmt.In(tx).Execute(4)
+	require.Nil(t, err)
+	require.Nil(t, tx.Commit())
+	assert.True(t, events[len(events)-1].InTransaction)
+
+	// Interceptors cannot rewrite prepared SQL
+	rewritten, err := db.WithInterceptor(func(s *Statement) error {
+		s.SQL += " LIMIT 1"
+		return nil
+	}).Prepare(users.Delete())
+	require.Nil(t, err)
+	_, err = rewritten.Execute()
+	assert.NotNil(t, err)
+}
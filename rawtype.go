@@ -0,0 +1,57 @@
+package aspect
+
+import "fmt"
+
+// RawType represents a column type that has no dedicated aspect type. Its
+// Name is output as is, such as NUMERIC(10, 2). It is used when reflecting
+// columns whose types are not otherwise supported.
+type RawType struct {
+	Name    string
+	NotNull bool
+	Default interface{}
+}
+
+var _ Type = RawType{}
+var _ Defaulter = RawType{}
+
+// Create returns the syntax need to create this column in CREATE statements.
+func (s RawType) Create(d Dialect) (string, error) {
+	if s.Name == "" {
+		return "", fmt.Errorf("aspect: raw types must have a name")
+	}
+	compiled := s.Name
+	if s.NotNull {
+		compiled += " NOT NULL"
+	}
+	if HasDefaultValue(s.Default) {
+		def, err := CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		compiled += fmt.Sprintf(" %s", def)
+	}
+	return compiled, nil
+}
+
+// HasDefault implements the Defaulter interface.
+func (s RawType) HasDefault() bool {
+	return HasDefaultValue(s.Default)
+}
+
+func (s RawType) IsPrimaryKey() bool {
+	return false
+}
+
+func (s RawType) IsRequired() bool {
+	return s.NotNull && !s.HasDefault()
+}
+
+func (s RawType) IsUnique() bool {
+	return false
+}
+
+// Validate accepts any value, since the database is responsible for
+// validating values of raw types.
+func (s RawType) Validate(i interface{}) (interface{}, error) {
+	return i, nil
+}
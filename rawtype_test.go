@@ -0,0 +1,20 @@
+package aspect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawType(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+	expect.Create("NUMERIC(10, 2)", RawType{Name: "NUMERIC(10, 2)"})
+	expect.Create(
+		"NUMERIC NOT NULL DEFAULT 0",
+		RawType{Name: "NUMERIC", NotNull: true, Default: 0},
+	)
+
+	// Raw types require a name
+	_, err := RawType{}.Create(&defaultDialect{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,287 @@
+package aspect
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Reflector is implemented by dialects that can describe the tables of an
+// existing database. Table names may be schema qualified, such as
+// audit.logs, and must be in the same form as the names of referenced
+// tables given in ForeignKeyInfo.
+type Reflector interface {
+	TableNames(conn Connection) ([]string, error)
+	ReflectTable(conn Connection, name string) (TableInfo, error)
+}
+
+// TableInfo is a dialect independent description of a database table.
+type TableInfo struct {
+	Name        string
+	Schema      string
+	Columns     []ColumnInfo
+	PrimaryKey  []string
+	Uniques     [][]string
+	ForeignKeys []ForeignKeyInfo
+}
+
+// ColumnInfo describes a single column of a reflected table.
+type ColumnInfo struct {
+	Name string
+	Type Type
+}
+
+// ForeignKeyInfo describes a single column foreign key of a reflected
+// table. Multi-column foreign keys are not supported by aspect and should
+// be omitted by reflectors.
+type ForeignKeyInfo struct {
+	Column    string
+	RefTable  string
+	RefColumn string
+	OnDelete  ForeignKeyAction
+	OnUpdate  ForeignKeyAction
+}
+
+// UnresolvedForeignKeys is returned by the reflection functions, along
+// with the reflected tables, when foreign keys could not be declared. A
+// table cannot reference a table that is still being reflected, so foreign
+// keys that form a cycle - and self references to columns that are
+// declared later in the table - are reflected as plain columns. The keys
+// are grouped by the name of the table that declares them.
+type UnresolvedForeignKeys map[string][]ForeignKeyInfo
+
+func (e UnresolvedForeignKeys) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var keys []string
+	for _, name := range names {
+		for _, fk := range e[name] {
+			keys = append(keys, fmt.Sprintf(
+				"%s.%s -> %s.%s", name, fk.Column, fk.RefTable, fk.RefColumn,
+			))
+		}
+	}
+	return fmt.Sprintf(
+		"aspect: foreign keys were reflected as plain columns: %s",
+		strings.Join(keys, ", "),
+	)
+}
+
+// Reflect builds a TableElem for the table with the given name from the
+// database of the given connection, which must report its dialect as DB
+// and TX do. Any tables referenced by foreign keys will also be reflected.
+// If any foreign keys could not be declared, the table is returned with
+// an UnresolvedForeignKeys error.
+func Reflect(conn Connection, name string) (*TableElem, error) {
+	r, err := newReflection(conn)
+	if err != nil {
+		return nil, err
+	}
+	table, err := r.table(name)
+	if err != nil {
+		return nil, err
+	}
+	return table, r.err()
+}
+
+// ReflectAll builds a TableElem for every table in the database of the
+// given connection and registers them with a new MetaData. If any foreign
+// keys could not be declared, the MetaData is returned with an
+// UnresolvedForeignKeys error.
+func ReflectAll(conn Connection) (*MetaData, error) {
+	r, err := newReflection(conn)
+	if err != nil {
+		return nil, err
+	}
+	return r.all()
+}
+
+// ReflectAllWith builds a TableElem for every table described by the given
+// Reflector, which will be given the connection. Reflectors that do not
+// require a database, such as those parsing DDL, may be given a nil
+// connection. Unresolved foreign keys are reported as with ReflectAll.
+func ReflectAllWith(reflector Reflector, conn Connection) (*MetaData, error) {
+	if reflector == nil {
+		return nil, fmt.Errorf("aspect: cannot reflect with a nil Reflector")
+	}
+	return newReflectionWith(reflector, conn).all()
+}
+
+func (r *reflection) all() (*MetaData, error) {
+	names, err := r.reflector.TableNames(r.conn)
+	if err != nil {
+		return nil, err
+	}
+	meta := NewMetaData()
+	for _, name := range names {
+		table, err := r.table(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := meta.Add(table); err != nil {
+			return nil, err
+		}
+	}
+	return meta, r.err()
+}
+
+// reflection caches reflected tables by name so that tables referenced by
+// multiple foreign keys are only reflected once.
+type reflection struct {
+	conn       Connection
+	reflector  Reflector
+	tables     map[string]*TableElem
+	pending    map[string]bool
+	unresolved UnresolvedForeignKeys
+}
+
+// err returns the foreign keys that could not be declared, if any
+func (r *reflection) err() error {
+	if len(r.unresolved) == 0 {
+		return nil
+	}
+	return r.unresolved
+}
+
+// unresolve reflects the column of the given foreign key as a plain
+// column and records the key
+func (r *reflection) unresolve(name string, column ColumnInfo, fk ForeignKeyInfo) TableModifier {
+	r.unresolved[name] = append(r.unresolved[name], fk)
+	return Column(column.Name, column.Type)
+}
+
+func (r *reflection) table(name string) (*TableElem, error) {
+	if table, ok := r.tables[name]; ok {
+		return table, nil
+	}
+	r.pending[name] = true
+	defer delete(r.pending, name)
+
+	info, err := r.reflector.ReflectTable(r.conn, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.Columns) == 0 {
+		return nil, fmt.Errorf("aspect: no table with the name %s exists", name)
+	}
+
+	fks := make(map[string]ForeignKeyInfo)
+	for _, fk := range info.ForeignKeys {
+		fks[fk.Column] = fk
+	}
+
+	var elements []TableModifier
+	if info.Schema != "" {
+		elements = append(elements, Schema(info.Schema))
+	}
+
+	declared := make(map[string]bool)
+	for _, column := range info.Columns {
+		element, err := r.column(name, column, fks, declared)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		declared[column.Name] = true
+	}
+
+	if len(info.PrimaryKey) > 0 {
+		elements = append(elements, PrimaryKey(info.PrimaryKey...))
+	}
+	for _, unique := range info.Uniques {
+		elements = append(elements, Unique(unique...))
+	}
+
+	table, err := newTable(info.Name, elements...)
+	if err != nil {
+		return nil, err
+	}
+	r.tables[name] = table
+	return table, nil
+}
+
+// column returns the element for the given column. Columns that reference
+// tables that are still being reflected - such as those in a cycle of
+// foreign keys - are reflected as plain columns and recorded as unresolved.
+func (r *reflection) column(name string, column ColumnInfo, fks map[string]ForeignKeyInfo, declared map[string]bool) (TableModifier, error) {
+	fk, ok := fks[column.Name]
+	if !ok {
+		return Column(column.Name, column.Type), nil
+	}
+
+	if fk.RefTable == name {
+		if !declared[fk.RefColumn] {
+			return r.unresolve(name, column, fk), nil
+		}
+		self := SelfForeignKey(column.Name, fk.RefColumn, column.Type)
+		self.ForeignKeyElem = withActions(self.ForeignKeyElem, fk)
+		return self, nil
+	}
+
+	if r.pending[fk.RefTable] {
+		return r.unresolve(name, column, fk), nil
+	}
+	ref, err := r.table(fk.RefTable)
+	if err != nil {
+		return nil, err
+	}
+	refColumn, ok := ref.C[fk.RefColumn]
+	if !ok {
+		return nil, fmt.Errorf(
+			"aspect: no column with the name %s exists in the table %s",
+			fk.RefColumn,
+			fk.RefTable,
+		)
+	}
+	return withActions(ForeignKey(column.Name, refColumn, column.Type), fk), nil
+}
+
+// withActions sets any reflected actions other than the default of
+// NO ACTION on the given foreign key.
+func withActions(elem ForeignKeyElem, fk ForeignKeyInfo) ForeignKeyElem {
+	if fk.OnDelete != "" && fk.OnDelete != NoAction {
+		elem = elem.OnDelete(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != NoAction {
+		elem = elem.OnUpdate(fk.OnUpdate)
+	}
+	return elem
+}
+
+// ParseAction converts a referential action reported by a database, such
+// as "SET NULL", to its aspect constant. Unknown actions return NO ACTION.
+func ParseAction(action string) ForeignKeyAction {
+	switch a := ForeignKeyAction(strings.ToUpper(strings.TrimSpace(action))); a {
+	case Restrict, Cascade, SetNull, SetDefault:
+		return a
+	}
+	return NoAction
+}
+
+func newReflection(conn Connection) (*reflection, error) {
+	d, err := ConnectionDialect(conn)
+	if err != nil {
+		return nil, err
+	}
+	reflector, ok := d.(Reflector)
+	if !ok {
+		return nil, fmt.Errorf(
+			"aspect: dialect %T does not support reflection", d,
+		)
+	}
+	return newReflectionWith(reflector, conn), nil
+}
+
+func newReflectionWith(reflector Reflector, conn Connection) *reflection {
+	return &reflection{
+		conn:       conn,
+		reflector:  reflector,
+		tables:     make(map[string]*TableElem),
+		pending:    make(map[string]bool),
+		unresolved: make(UnresolvedForeignKeys),
+	}
+}
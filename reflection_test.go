@@ -0,0 +1,145 @@
+package aspect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReflector map[string]TableInfo
+
+func (r fakeReflector) TableNames(conn Connection) ([]string, error) {
+	var names []string
+	for name := range r {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (r fakeReflector) ReflectTable(conn Connection, name string) (TableInfo, error) {
+	return r[name], nil
+}
+
+func TestReflect(t *testing.T) {
+	// Foreign keys that form a cycle are reflected as plain columns
+	reflector := fakeReflector{
+		"teams": {
+			Name: "teams",
+			Columns: []ColumnInfo{
+				{Name: "id", Type: Integer{NotNull: true}},
+				{Name: "captain_id", Type: Integer{}},
+			},
+			PrimaryKey: []string{"id"},
+			ForeignKeys: []ForeignKeyInfo{
+				{Column: "captain_id", RefTable: "players", RefColumn: "id"},
+			},
+		},
+		"players": {
+			Name:   "players",
+			Schema: "league",
+			Columns: []ColumnInfo{
+				{Name: "id", Type: Integer{NotNull: true}},
+				{Name: "team_id", Type: Integer{}},
+				{Name: "coach_id", Type: Integer{}},
+			},
+			PrimaryKey: []string{"id"},
+			Uniques:    [][]string{{"team_id", "coach_id"}},
+			ForeignKeys: []ForeignKeyInfo{
+				{
+					Column:    "team_id",
+					RefTable:  "teams",
+					RefColumn: "id",
+					OnDelete:  ParseAction("set null"),
+				},
+				{Column: "coach_id", RefTable: "players", RefColumn: "id"},
+			},
+		},
+	}
+	r := newReflectionWith(reflector, nil)
+
+	teams, err := r.table("teams")
+	require.Nil(t, err)
+
+	expect := NewTester(t, &defaultDialect{})
+	expect.SQL(
+		`CREATE TABLE "teams" (
+  "id" INTEGER NOT NULL,
+  "captain_id" INTEGER REFERENCES "league"."players"("id"),
+  PRIMARY KEY ("id")
+);`,
+		teams.Create(),
+	)
+	expect.SQL(
+		`CREATE TABLE "league"."players" (
+  "id" INTEGER NOT NULL,
+  "team_id" INTEGER,
+  "coach_id" INTEGER REFERENCES "league"."players"("id"),
+  PRIMARY KEY ("id"),
+  UNIQUE ("team_id", "coach_id")
+);`,
+		r.tables["players"].Create(),
+	)
+
+	// The foreign key that was dropped is reported
+	assert.Equal(t,
+		UnresolvedForeignKeys{"players": {reflector["players"].ForeignKeys[0]}},
+		r.err(),
+	)
+	assert.Equal(t,
+		"aspect: foreign keys were reflected as plain columns: players.team_id -> teams.id",
+		r.err().Error(),
+	)
+
+	// The tables are still returned along with the error
+	meta, err := ReflectAllWith(reflector, nil)
+	var unresolved UnresolvedForeignKeys
+	require.True(t, errors.As(err, &unresolved))
+	assert.Equal(t, 1, len(unresolved))
+	require.NotNil(t, meta)
+	assert.Equal(t, 2, len(meta.Tables()))
+
+	// Self references to columns that are declared later are also reported
+	r = newReflectionWith(fakeReflector{
+		"nodes": {
+			Name: "nodes",
+			Columns: []ColumnInfo{
+				{Name: "parent_id", Type: Integer{}},
+				{Name: "id", Type: Integer{NotNull: true}},
+			},
+			ForeignKeys: []ForeignKeyInfo{
+				{Column: "parent_id", RefTable: "nodes", RefColumn: "id"},
+			},
+		},
+	}, nil)
+	_, err = r.table("nodes")
+	require.Nil(t, err)
+	assert.Equal(t,
+		"aspect: foreign keys were reflected as plain columns: nodes.parent_id -> nodes.id",
+		r.err().Error(),
+	)
+
+	_, err = r.table("missing")
+	assert.NotNil(t, err)
+
+	assert.Equal(t, NoAction, ParseAction("unknown"))
+	assert.Equal(t, SetNull, ParseAction("SET NULL"))
+}
+
+// plainConnection implements Connection without reporting a dialect
+type plainConnection struct {
+	Connection
+}
+
+func TestConnectionDialect(t *testing.T) {
+	d := &defaultDialect{}
+	dialect, err := ConnectionDialect(&DB{dialect: d})
+	require.Nil(t, err)
+	assert.Equal(t, d, dialect)
+
+	_, err = ConnectionDialect(plainConnection{})
+	assert.NotNil(t, err)
+	_, err = Reflect(plainConnection{}, "users")
+	assert.NotNil(t, err)
+}
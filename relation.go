@@ -0,0 +1,298 @@
+package aspect
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// RelationBatchSize is the most keys a relation will query with a single
+// IN query. Relations of more structs are loaded with additional queries.
+// The default is the lowest parameter limit of the supported databases,
+// which is 999 for sqlite3 before version 3.32.
+var RelationBatchSize = 999
+
+// Relation loads the related rows of a foreign key into a field of
+// already queried structs with a single additional IN query for up to
+// RelationBatchSize keys, avoiding a query per struct.
+type Relation struct {
+	fk    ForeignKeyElem
+	field string
+	many  bool
+	order []Orderable
+	err   error
+}
+
+// HasMany creates a relation that loads the rows of the foreign key
+// column's table into a slice field of the structs of the referenced table.
+// For example, the posts of users:
+//
+//  type User struct {
+//      ID    int64  `db:"id"`
+//      Posts []Post `db:"-"`
+//  }
+//
+//  var UserPosts = aspect.HasMany(Posts.C["user_id"], "Posts")
+//  err := UserPosts.Load(conn, &users)
+//
+// The slice field may hold structs or pointers to structs.
+func HasMany(column ColumnElem, field string) Relation {
+	return newRelation(column, field, true)
+}
+
+// BelongsTo creates a relation that loads the referenced row of the foreign
+// key column into a struct or pointer to struct field of the structs of the
+// column's table. For example, the author of posts:
+//
+//  type Post struct {
+//      UserID int64 `db:"user_id"`
+//      User   *User `db:"-"`
+//  }
+//
+//  var PostUser = aspect.BelongsTo(Posts.C["user_id"], "User")
+//  err := PostUser.Load(conn, &posts)
+//
+// Pointer fields are left nil if no row is referenced.
+func BelongsTo(column ColumnElem, field string) Relation {
+	return newRelation(column, field, false)
+}
+
+func newRelation(column ColumnElem, field string, many bool) Relation {
+	relation := Relation{field: field, many: many}
+	if column.table == nil {
+		relation.err = fmt.Errorf(
+			"aspect: relations require a column of a table - does the column exist?",
+		)
+		return relation
+	}
+	for _, fk := range column.table.ForeignKeys() {
+		if fk.Name() == column.Name() {
+			relation.fk = fk
+			return relation
+		}
+	}
+	relation.err = fmt.Errorf(
+		"aspect: the column %s.%s is not a foreign key",
+		column.table.Name(), column.Name(),
+	)
+	return relation
+}
+
+// OrderBy adds an ORDER BY clause to the query of related rows. The order
+// is kept within each slice of a HasMany relation.
+func (relation Relation) OrderBy(params ...Orderable) Relation {
+	relation.order = append(relation.order, params...)
+	return relation
+}
+
+// Load queries the related rows of the given destination, which must be a
+// pointer to a slice of structs, a pointer to a slice of pointers to
+// structs, or a pointer to a single struct, and sets them on the field of
+// the relation.
+func (relation Relation) Load(conn Connection, dest interface{}) error {
+	if relation.err != nil {
+		return relation.err
+	}
+
+	// Collect the addressable structs of the destination
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("aspect: relations must be loaded into a pointer")
+	}
+	var elems []reflect.Value
+	switch value = value.Elem(); value.Kind() {
+	case reflect.Struct:
+		elems = append(elems, value)
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			elem := value.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Struct {
+				return fmt.Errorf(
+					"aspect: relations cannot be loaded into %s", value.Type(),
+				)
+			}
+			elems = append(elems, elem)
+		}
+	default:
+		return fmt.Errorf(
+			"aspect: relations cannot be loaded into %s", value.Type(),
+		)
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+
+	// The owner table's key column and the related table's key column
+	ownerKey, relatedKey := relation.fk.col, relation.fk.table.C[relation.fk.name]
+	if !relation.many {
+		ownerKey, relatedKey = relatedKey, ownerKey
+	}
+
+	target, ok := elems[0].Type().FieldByName(relation.field)
+	if !ok || target.PkgPath != "" {
+		return fmt.Errorf(
+			"aspect: %s has no exported field %s", elems[0].Type(), relation.field,
+		)
+	}
+
+	// The type of the related structs
+	related := target.Type
+	if relation.many {
+		if related.Kind() != reflect.Slice {
+			return fmt.Errorf(
+				"aspect: the field %s of a HasMany relation must be a slice",
+				relation.field,
+			)
+		}
+		related = related.Elem()
+	}
+	if related.Kind() == reflect.Ptr {
+		related = related.Elem()
+	}
+	if related.Kind() != reflect.Struct {
+		return fmt.Errorf(
+			"aspect: the field %s must hold structs", relation.field,
+		)
+	}
+
+	ownerField, err := keyField(elems[0].Type(), ownerKey)
+	if err != nil {
+		return err
+	}
+	relatedField, err := keyField(related, relatedKey)
+	if err != nil {
+		return err
+	}
+
+	// Collect every unique key
+	var keys []interface{}
+	seen := make(map[interface{}]bool)
+	for _, elem := range elems {
+		key := keyOf(fieldByIndex(elem, ownerField.index, false))
+		if key != nil && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	// Query the related rows in batches, since databases limit the parameters of a statement. The
+	// rows of each key are queried together, so their order is kept.
+	size := RelationBatchSize
+	if size < 1 {
+		size = len(keys)
+	}
+	rows := reflect.MakeSlice(reflect.SliceOf(related), 0, 0)
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		stmt := relatedKey.table.Select().Where(relatedKey.In(keys[start:end]))
+		if len(relation.order) > 0 {
+			stmt = stmt.OrderBy(relation.order...)
+		}
+		batch := reflect.New(reflect.SliceOf(related))
+		if err := conn.QueryAll(stmt, batch.Interface()); err != nil {
+			return err
+		}
+		rows = reflect.AppendSlice(rows, batch.Elem())
+	}
+
+	// Group the related rows by their key
+	byKey := make(map[interface{}][]reflect.Value)
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		key := keyOf(fieldByIndex(row, relatedField.index, false))
+		byKey[key] = append(byKey[key], row)
+	}
+
+	for _, elem := range elems {
+		key := keyOf(fieldByIndex(elem, ownerField.index, false))
+		matches := byKey[key]
+		if key == nil {
+			matches = nil
+		}
+		field := elem.FieldByIndex(target.Index)
+		if relation.many {
+			slice := reflect.MakeSlice(target.Type, 0, len(matches))
+			for _, match := range matches {
+				slice = reflect.Append(slice, asType(match, target.Type.Elem()))
+			}
+			field.Set(slice)
+		} else if len(matches) > 0 {
+			field.Set(asType(matches[0], target.Type))
+		} else {
+			field.Set(reflect.Zero(target.Type))
+		}
+	}
+	return nil
+}
+
+// keyField returns the field of the given struct type for the key column
+func keyField(elem reflect.Type, column ColumnElem) (field, error) {
+	aligned, _ := cachedAlignment(
+		elem, []string{column.table.Name() + "." + column.name},
+	)
+	if !aligned[0].Exists() {
+		return field{}, fmt.Errorf(
+			"aspect: %s has no field for the column %s.%s",
+			elem, column.table.Name(), column.name,
+		)
+	}
+	return aligned[0], nil
+}
+
+// asType returns the given struct value as either the struct or a pointer
+// to a copy of the struct
+func asType(v reflect.Value, t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Ptr {
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr
+	}
+	return v
+}
+
+// keyOf returns a comparable value of the given key field so that keys of
+// different Go types, such as int64 and sql.NullInt64, are equal. Invalid
+// fields and NULL values return nil.
+func keyOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	i := v.Interface()
+	if valuer, ok := i.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil || value == nil {
+			return nil
+		}
+		v = reflect.ValueOf(value)
+		i = value
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Slice:
+		if b, ok := i.([]byte); ok {
+			return string(b)
+		}
+		return nil
+	}
+	if !v.Type().Comparable() {
+		return nil
+	}
+	return i
+}
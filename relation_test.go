@@ -0,0 +1,50 @@
+package aspect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parent struct {
+	ID       int64   `db:"id"`
+	Children []child `db:"-"`
+	Name     string  `db:"name"`
+}
+
+type child struct {
+	ParentID int64  `db:"parent_id"`
+	Parent   parent `db:"-"`
+}
+
+func TestRelation(t *testing.T) {
+	// Relations require foreign keys
+	assert.NotNil(t, HasMany(parents.C["id"], "Children").err)
+	assert.NotNil(t, HasMany(ColumnElem{}, "Children").err)
+	assert.Nil(t, HasMany(children.C["parent_id"], "Children").err)
+
+	// Destinations and fields are checked before any query
+	relation := HasMany(children.C["parent_id"], "Children")
+	assert.NotNil(t, relation.Load(nil, parent{}))
+	assert.NotNil(t, relation.Load(nil, &[]int64{1}))
+	assert.NotNil(t, HasMany(children.C["parent_id"], "Name").Load(
+		nil, &[]parent{{ID: 1}},
+	))
+	assert.NotNil(t, HasMany(children.C["parent_id"], "Missing").Load(
+		nil, &[]parent{{ID: 1}},
+	))
+	assert.NotNil(t, BelongsTo(children.C["parent_id"], "Parent").Load(
+		nil, &[]parent{{ID: 1}},
+	))
+
+	// Empty destinations do not query
+	assert.Nil(t, relation.Load(nil, &[]parent{}))
+}
+
+func TestKeyOf(t *testing.T) {
+	assert.Equal(t, int64(1), keyOf(reflect.ValueOf(int32(1))))
+	assert.Equal(t, int64(1), keyOf(reflect.ValueOf(uint(1))))
+	assert.Equal(t, "a", keyOf(reflect.ValueOf([]byte("a"))))
+	assert.Nil(t, keyOf(reflect.ValueOf((*int64)(nil))))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 var (
@@ -23,8 +24,166 @@ type Scanner interface {
 }
 
 type Result struct {
-	stmt string
-	rows Scanner
+	stmt       string
+	args       []interface{}
+	rows       Scanner
+	strict     bool
+	cols       []string
+	names      []string // Table qualified names of the columns, if known
+	key        int      // Index of the column that keys map results
+	converters Converters
+}
+
+// resultNamer is implemented by statements that know the table qualified
+// names of their result columns, such as SelectStmt, and which column is
+// the key of results
+type resultNamer interface {
+	resultNames() []string
+	resultKey() int
+}
+
+func newResult(rows Scanner, s string, args []interface{}, stmt Executable, strict bool, converters Converters) *Result {
+	// Destinations of converted types are converted by wrapping the rows
+	if !converters.empty() {
+		rows = convertedRows{Scanner: rows, converters: converters}
+	}
+	result := &Result{
+		rows:       rows,
+		stmt:       s,
+		args:       args,
+		strict:     strict,
+		converters: converters,
+	}
+	if namer, ok := stmt.(resultNamer); ok {
+		result.names = namer.resultNames()
+		result.key = namer.resultKey()
+	}
+	return result
+}
+
+// SQL returns the SQL that was queried for the result
+func (r *Result) SQL() string {
+	return r.stmt
+}
+
+// Args returns the parameters that were queried with the SQL of the result
+func (r *Result) Args() []interface{} {
+	return r.args
+}
+
+// Strict will cause the result to return an error instead of discarding
+// selected columns that do not match a field of a destination struct.
+func (r *Result) Strict() *Result {
+	r.strict = true
+	return r
+}
+
+// align returns the cached fields of the given struct type aligned to the
+// columns of the result. Unmatched columns will be discarded unless the
+// result is strict.
+func (r *Result) align(elem reflect.Type, columns []string) (fields, error) {
+	// Columns are qualified by their tables if known
+	names := columns
+	if len(r.names) == len(columns) {
+		names = r.names
+		for i, name := range r.names {
+			if _, column := splitName(name); column != columns[i] {
+				names = columns
+				break
+			}
+		}
+	}
+	aligned, fields := cachedAlignment(elem, names)
+
+	// If the aligned struct is empty, fallback to matching the fields in
+	// order, but only if the length of the columns equals the fields
+	if aligned.Empty() && len(columns) == len(fields) {
+		aligned = fields
+	}
+	if err := r.checkAligned(columns, aligned); err != nil {
+		return nil, err
+	}
+	return aligned, nil
+}
+
+// scanStruct scans the current row into the aligned fields of the given
+// struct value. Fields within pointers to structs are scanned into
+// temporary values so that pointers whose fields are all NULL - such as
+// the missing side of an outer join - can be left nil.
+func scanStruct(rows Scanner, elem reflect.Value, aligned fields) error {
+	dest := make([]interface{}, len(aligned))
+	var temps []reflect.Value
+	for i, field := range aligned {
+		// If the field does not exist, the value will be discarded
+		if !field.Exists() {
+			dest[i] = &dest[i]
+			continue
+		}
+		if field.ptr {
+			if temps == nil {
+				temps = make([]reflect.Value, len(aligned))
+			}
+			t := elem.Type().FieldByIndex(field.index).Type
+			temps[i] = reflect.New(reflect.PtrTo(t))
+			dest[i] = temps[i].Interface()
+			continue
+		}
+		dest[i] = fieldByIndex(elem, field.index, true).Addr().Interface()
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	if temps == nil {
+		return nil
+	}
+
+	// Reset the pointers of the scanned fields before setting any non-NULL
+	// values, which will allocate them
+	for i, temp := range temps {
+		if temp.IsValid() {
+			resetPointers(elem, aligned[i].index)
+		}
+	}
+	for i, temp := range temps {
+		if temp.IsValid() && !temp.Elem().IsNil() {
+			fieldByIndex(elem, aligned[i].index, true).Set(temp.Elem().Elem())
+		}
+	}
+	return nil
+}
+
+// resetPointers sets the outermost pointer to a struct along the given
+// index to nil
+func resetPointers(v reflect.Value, index []int) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		v = v.Field(x)
+	}
+}
+
+// checkAligned returns an error if the result is strict and any of the
+// columns does not have an aligned field
+func (r *Result) checkAligned(columns []string, aligned fields) error {
+	if !r.strict {
+		return nil
+	}
+	var unmatched []string
+	for i, field := range aligned {
+		if !field.Exists() {
+			unmatched = append(unmatched, columns[i])
+		}
+	}
+	if len(unmatched) > 0 {
+		return fmt.Errorf(
+			"aspect: strict result has columns without fields: %s",
+			strings.Join(unmatched, ", "),
+		)
+	}
+	return nil
 }
 
 func (r *Result) Close() error {
@@ -35,21 +194,69 @@ func (r *Result) Next() bool {
 	return r.rows.Next()
 }
 
+// Err returns any error encountered during iteration of the result rows.
+// It should be checked after Next returns false.
+func (r *Result) Err() error {
+	return r.rows.Err()
+}
+
 // One returns a single row from Result. The destination interface must be
 // a pointer to a struct or a native type.
 func (r *Result) One(arg interface{}) error {
 	// Confirm that there is at least one row to return
 	if ok := r.rows.Next(); !ok {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
 		return ErrNoResult
 	}
+	if err := r.Scan(arg); err != nil {
+		return err
+	}
+	return r.rows.Err()
+}
 
+// columns returns the columns of the result, which are only requested from
+// the rows once
+func (r *Result) columns() ([]string, error) {
+	if r.cols != nil {
+		return r.cols, nil
+	}
 	columns, err := r.rows.Columns()
 	if err != nil {
-		return fmt.Errorf(
+		return nil, fmt.Errorf(
 			"aspect: error returning columns from result: %s",
 			err,
 		)
 	}
+	r.cols = columns
+	return columns, nil
+}
+
+// Scan scans the current row into the given destination, which must be a
+// pointer to a struct or a native type, or an instance of (or pointer to)
+// Values. Next must be called before each Scan:
+//
+//  for result.Next() {
+//      if err := result.Scan(&user); err != nil {
+//          return err
+//      }
+//  }
+//  return result.Err()
+//
+func (r *Result) Scan(arg interface{}) error {
+	columns, err := r.columns()
+	if err != nil {
+		return err
+	}
+
+	// Pointers to Values are initialized if nil
+	if ptr, ok := arg.(*Values); ok && ptr != nil {
+		if *ptr == nil {
+			*ptr = Values{}
+		}
+		arg = *ptr
+	}
 
 	value := reflect.ValueOf(arg)
 	if value.Kind() == reflect.Map {
@@ -72,54 +279,27 @@ func (r *Result) One(arg interface{}) error {
 		for i, name := range columns {
 			values[name] = addr[i]
 		}
-		return r.rows.Err()
+		return nil
 
 	} else if value.Kind() != reflect.Ptr {
 		return fmt.Errorf(
-			"aspect: received a non-pointer destination for result.One",
+			"aspect: received a non-pointer destination for result.Scan",
 		)
 	}
 
 	// Get the value of the given interface
 	elem := reflect.Indirect(value)
 
-	switch elem.Kind() {
+	switch r.converters.destKind(elem.Type()) {
 	case reflect.Struct:
-		// Build the fields of the given struct
-		// TODO this operation could be cached
-		fields := SelectFields(arg)
-
-		// Align the fields to the selected columns
-		// This will discard unmatched fields
-		// TODO strict mode? error if not all columns were matched?
-		aligned := AlignColumns(columns, fields)
-
-		// If the aligned struct is empty, fallback to matching the fields in
-		// order, but only if the length of the columns equals the fields
-		if aligned.Empty() && len(columns) == len(fields) {
-			aligned = fields
+		aligned, err := r.align(elem.Type(), columns)
+		if err != nil {
+			return err
 		}
-
-		// Get an interface for each field and save a pointer to it
-		dest := make([]interface{}, len(aligned))
-		for i, field := range aligned {
-			// If the field does not exist, the value will be discarded
-			if !field.Exists() {
-				dest[i] = &dest[i]
-				continue
-			}
-
-			// Recursively get an interface to the elem's fields
-			var fieldElem reflect.Value = elem
-			for _, index := range field.index {
-				fieldElem = fieldElem.Field(index)
-			}
-			dest[i] = fieldElem.Addr().Interface()
-		}
-
-		if err := r.rows.Scan(dest...); err != nil {
+		if err := scanStruct(r.rows, elem, aligned); err != nil {
 			return fmt.Errorf("aspect: error while scanning struct: %s", err)
 		}
+		return nil
 
 	case reflect.Slice:
 		return fmt.Errorf("aspect: cannot scan single results into slices")
@@ -134,11 +314,62 @@ func (r *Result) One(arg interface{}) error {
 		// Attempt to scan directly into the elem
 		return r.rows.Scan(elem.Addr().Interface())
 	}
+}
+
+// Each calls the given function with every remaining row of the result.
+// The function must have the signature func(*T) error, where *T is a valid
+// destination for Scan. A single destination is reused for each row, so it
+// should be copied if retained. Iteration stops at the first error returned
+// by the function, which is then returned. The result is always closed.
+//
+//  err := result.Each(func(user *User) error {
+//      return encoder.Encode(user)
+//  })
+//
+func (r *Result) Each(f interface{}) (err error) {
+	defer func() {
+		if closeErr := r.rows.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	fn := reflect.ValueOf(f)
+	t := reflect.TypeOf(f)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 1 ||
+		t.In(0).Kind() != reflect.Ptr || t.Out(0) != errorType || fn.IsNil() {
+		return fmt.Errorf(
+			"aspect: result.Each requires a func(*T) error, received %T", f,
+		)
+	}
+
+	dest := reflect.New(t.In(0).Elem())
+	zero := reflect.Zero(t.In(0).Elem())
+	args := []reflect.Value{dest}
+	for r.rows.Next() {
+		// Reset the destination so that no values of the previous row remain
+		dest.Elem().Set(zero)
+		if err := r.Scan(dest.Interface()); err != nil {
+			return err
+		}
+		if out := fn.Call(args)[0]; !out.IsNil() {
+			return out.Interface().(error)
+		}
+	}
 	return r.rows.Err()
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // All returns all result rows into the given interface, which must be a
-// pointer to a slice of either structs, values, or a native type.
+// pointer to one of:
+//
+//  a slice of structs, Values, or a native type for single column results
+//  a map of structs, or pointers to structs, keyed by the primary key
+//  a map of native types for two column results, keyed by the first column
+//  a struct of slices, which will receive the values of each column
+//
+// Maps are keyed by the first selected column that is the sole primary key
+// of its table, or otherwise by the first column.
 func (r *Result) All(arg interface{}) error {
 	argVal := reflect.ValueOf(arg)
 	if argVal.Kind() != reflect.Ptr {
@@ -148,7 +379,13 @@ func (r *Result) All(arg interface{}) error {
 	}
 
 	argElem := argVal.Elem()
-	if argElem.Kind() != reflect.Slice {
+	switch r.converters.destKind(argElem.Type()) {
+	case reflect.Map:
+		return r.allMap(argElem)
+	case reflect.Struct:
+		return r.allColumns(argElem)
+	case reflect.Slice:
+	default:
 		return fmt.Errorf(
 			"aspect: receive a non-slice destination for result.All",
 		)
@@ -157,30 +394,17 @@ func (r *Result) All(arg interface{}) error {
 	// Get the type of the slice element
 	elem := argElem.Type().Elem()
 
-	columns, err := r.rows.Columns()
+	columns, err := r.columns()
 	if err != nil {
-		return fmt.Errorf(
-			"aspect: error returning columns from result: %s",
-			err,
-		)
+		return err
 	}
 
-	switch elem.Kind() {
+	switch r.converters.destKind(elem) {
 	case reflect.Struct:
 
-		// Build the fields of the given struct
-		// TODO this operation could be cached
-		fields := SelectFieldsFromElem(elem)
-
-		// Align the fields to the selected columns
-		// This will discard unmatched fields
-		// TODO struct mode? error if not all columns were matched?
-		aligned := AlignColumns(columns, fields)
-
-		// If the aligned struct is empty, fallback to matching the fields in
-		// order, but only if the length of the columns equals the fields
-		if aligned.Empty() && len(columns) == len(fields) {
-			aligned = fields
+		aligned, err := r.align(elem, columns)
+		if err != nil {
+			return err
 		}
 
 		// Is there an existing slice element for this result?
@@ -192,50 +416,13 @@ func (r *Result) All(arg interface{}) error {
 		for r.rows.Next() {
 			if scanned < n {
 				// Scan into an existing element
-				newElem := argElem.Index(scanned)
-
-				// Get an interface for each field and save a pointer to it
-				dest := make([]interface{}, len(aligned))
-				for i, field := range aligned {
-					// If the field does not exist, the value will be discarded
-					if !field.Exists() {
-						dest[i] = &dest[i]
-						continue
-					}
-
-					// Recursively get an interface to the elem's fields
-					var fieldElem reflect.Value = newElem
-					for _, index := range field.index {
-						fieldElem = fieldElem.Field(index)
-					}
-					dest[i] = fieldElem.Addr().Interface()
-				}
-
-				if err := r.rows.Scan(dest...); err != nil {
+				if err := scanStruct(r.rows, argElem.Index(scanned), aligned); err != nil {
 					return err
 				}
 			} else {
 				// Create a new slice element
 				newElem := reflect.New(elem).Elem()
-
-				// Get an interface for each field and save a pointer to it
-				dest := make([]interface{}, len(aligned))
-				for i, field := range aligned {
-					// If the field does not exist, the value will be discarded
-					if !field.Exists() {
-						dest[i] = &dest[i]
-						continue
-					}
-
-					// Recursively get an interface to the elem's fields
-					var fieldElem reflect.Value = newElem
-					for _, index := range field.index {
-						fieldElem = fieldElem.Field(index)
-					}
-					dest[i] = fieldElem.Addr().Interface()
-				}
-
-				if err := r.rows.Scan(dest...); err != nil {
+				if err := scanStruct(r.rows, newElem, aligned); err != nil {
 					return err
 				}
 				argElem.Set(reflect.Append(argElem, newElem))
@@ -317,3 +504,119 @@ func (r *Result) All(arg interface{}) error {
 
 	return r.rows.Err()
 }
+
+// allMap scans all result rows into the given map
+func (r *Result) allMap(m reflect.Value) error {
+	columns, err := r.columns()
+	if err != nil {
+		return err
+	}
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	keyType, valueType := m.Type().Key(), m.Type().Elem()
+
+	// Structs are keyed by the field of the key column
+	elem := valueType
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if isNested(elem) {
+		aligned, err := r.align(elem, columns)
+		if err != nil {
+			return err
+		}
+		key := aligned[r.key]
+		if !key.Exists() {
+			return fmt.Errorf(
+				"aspect: %s has no field for the key column %s",
+				elem, columns[r.key],
+			)
+		}
+		keyField := elem.FieldByIndex(key.index)
+		if !keyField.Type.ConvertibleTo(keyType) {
+			return fmt.Errorf(
+				"aspect: the key column %s of type %s cannot be a %s map key",
+				columns[r.key], keyField.Type, keyType,
+			)
+		}
+
+		for r.rows.Next() {
+			value := reflect.New(elem)
+			if err := scanStruct(r.rows, value.Elem(), aligned); err != nil {
+				return err
+			}
+			k := fieldByIndex(value.Elem(), key.index, false)
+			if valueType.Kind() != reflect.Ptr {
+				value = value.Elem()
+			}
+			m.SetMapIndex(k.Convert(keyType), value)
+		}
+		return r.rows.Err()
+	}
+
+	if len(columns) != 2 {
+		return fmt.Errorf(
+			"aspect: maps of %s require two column results, received %d columns",
+			valueType, len(columns),
+		)
+	}
+	for r.rows.Next() {
+		k, v := reflect.New(keyType), reflect.New(valueType)
+		if err := r.rows.Scan(k.Interface(), v.Interface()); err != nil {
+			return err
+		}
+		m.SetMapIndex(k.Elem(), v.Elem())
+	}
+	return r.rows.Err()
+}
+
+// allColumns appends the value of each column to the aligned slice field of
+// the given struct
+func (r *Result) allColumns(elem reflect.Value) error {
+	columns, err := r.columns()
+	if err != nil {
+		return err
+	}
+	aligned, err := r.align(elem.Type(), columns)
+	if err != nil {
+		return err
+	}
+
+	slices := make([]reflect.Value, len(aligned))
+	for i, field := range aligned {
+		if !field.Exists() {
+			continue
+		}
+		slices[i] = fieldByIndex(elem, field.index, true)
+		if slices[i].Kind() != reflect.Slice || slices[i].Type() == bytesType {
+			return fmt.Errorf(
+				"aspect: the field for column %s must be a slice to receive all results",
+				columns[i],
+			)
+		}
+	}
+
+	for r.rows.Next() {
+		// Scan into a new value of each slice element
+		dest := make([]interface{}, len(aligned))
+		values := make([]reflect.Value, len(aligned))
+		for i := range aligned {
+			if !slices[i].IsValid() {
+				dest[i] = &dest[i]
+				continue
+			}
+			values[i] = reflect.New(slices[i].Type().Elem())
+			dest[i] = values[i].Interface()
+		}
+		if err := r.rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			if value.IsValid() {
+				slices[i].Set(reflect.Append(slices[i], value.Elem()))
+			}
+		}
+	}
+	return r.rows.Err()
+}
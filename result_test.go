@@ -12,9 +12,11 @@ type mockScanner struct {
 	columns []string
 	n       int   // Default number of results
 	err     error // Allow an error to be set
+	closed  bool
 }
 
 func (m *mockScanner) Close() error {
+	m.closed = true
 	return nil
 }
 
@@ -174,3 +176,142 @@ func TestResult(t *testing.T) {
 	var simpletonErrors []simpleUser
 	assert.NotNil(result.One(&simpletonErrors))
 }
+
+func TestResult_Strict(t *testing.T) {
+	// Strict results error on columns without fields
+	result := newMockResult("id", "name", "email").Strict()
+	var extra extraUser
+	assert.NotNil(t, result.One(&extra))
+
+	result = newMockResult("id", "name", "email").Strict()
+	var extras []extraUser
+	assert.NotNil(t, result.All(&extras))
+
+	// Fields without columns are allowed
+	result = newMockResult("id", "name").Strict()
+	assert.Nil(t, result.One(&extra))
+
+	result = newMockResult("id", "name").Strict()
+	assert.Nil(t, result.All(&extras))
+	assert.Equal(t, 2, len(extras))
+}
+
+func TestResult_Scan(t *testing.T) {
+	result := newMockResultN(3, "id", "name")
+	var users []extraUser
+	for result.Next() {
+		var user extraUser
+		assert.Nil(t, result.Scan(&user))
+		users = append(users, user)
+	}
+	assert.Nil(t, result.Err())
+	assert.Equal(t, 3, len(users))
+
+	// Values can be scanned with or without a pointer
+	result = newMockResultN(1, "id", "name")
+	var values Values
+	assert.True(t, result.Next())
+	assert.Nil(t, result.Scan(&values))
+	assert.Equal(t, Values{"id": nil, "name": nil}, values)
+
+	// Non-pointers cannot be scanned
+	assert.NotNil(t, result.Scan(extraUser{}))
+
+	// Row errors are surfaced
+	result = newMockResultN(0, "id", "name")
+	result.rows.(*mockScanner).err = fmt.Errorf("connection reset")
+	var user extraUser
+	assert.Equal(t, "connection reset", result.One(&user).Error())
+}
+
+func TestResult_Each(t *testing.T) {
+	result := newMockResultN(3, "id", "name")
+	var n int
+	assert.Nil(t, result.Each(func(user *extraUser) error {
+		n += 1
+		return nil
+	}))
+	assert.Equal(t, 3, n)
+	assert.True(t, result.rows.(*mockScanner).closed)
+
+	// Errors stop iteration and close the rows
+	result = newMockResultN(3, "id", "name")
+	n = 0
+	err := result.Each(func(user *extraUser) error {
+		n += 1
+		return fmt.Errorf("stop")
+	})
+	assert.Equal(t, "stop", err.Error())
+	assert.Equal(t, 1, n)
+	assert.True(t, result.rows.(*mockScanner).closed)
+
+	// Row errors are returned after iteration
+	result = newMockResultN(2, "id")
+	result.rows.(*mockScanner).err = fmt.Errorf("connection reset")
+	var ids []int64
+	err = result.Each(func(id *int64) error {
+		ids = append(ids, *id)
+		return nil
+	})
+	assert.Equal(t, "connection reset", err.Error())
+	assert.Equal(t, 2, len(ids))
+
+	// Values are given a new map for each row
+	result = newMockResultN(2, "id")
+	assert.Nil(t, result.Each(func(values *Values) error {
+		assert.Equal(t, 1, len(*values))
+		return nil
+	}))
+
+	// Functions must be func(*T) error
+	result = newMockResultN(1, "id")
+	assert.NotNil(t, result.Each(func(id int64) error { return nil }))
+	assert.NotNil(t, result.Each(func(id *int64) {}))
+	assert.NotNil(t, result.Each(nil))
+}
+
+func TestResult_AllShapes(t *testing.T) {
+	// Maps of structs are keyed by the first column
+	result := newMockResult("id", "name")
+	var byID map[int64]extraUser
+	assert.Nil(t, result.All(&byID))
+	assert.Equal(t, 1, len(byID))
+
+	result = newMockResult("id", "name")
+	var ptrs map[int64]*extraUser
+	assert.Nil(t, result.All(&ptrs))
+	assert.Equal(t, 1, len(ptrs))
+
+	// The key column must have a field
+	result = newMockResult("name", "id")
+	assert.NotNil(t, result.All(&byID))
+
+	// Maps of native types require two columns
+	result = newMockResult("name", "count")
+	var counts map[string]int
+	assert.Nil(t, result.All(&counts))
+	assert.Equal(t, 1, len(counts))
+
+	result = newMockResult("name")
+	assert.NotNil(t, result.All(&counts))
+
+	// Structs of slices receive each column
+	var columns struct {
+		IDs   []int64  `db:"id"`
+		Names []string `db:"name"`
+	}
+	result = newMockResultN(3, "id", "name", "password")
+	assert.Nil(t, result.All(&columns))
+	assert.Equal(t, 3, len(columns.IDs))
+	assert.Equal(t, 3, len(columns.Names))
+
+	var notSlices struct {
+		ID int64 `db:"id"`
+	}
+	result = newMockResult("id")
+	assert.NotNil(t, result.All(&notSlices))
+
+	// Other destinations are rejected
+	var id int64
+	assert.NotNil(t, newMockResult("id").All(&id))
+}
@@ -0,0 +1,40 @@
+package aspect
+
+import "fmt"
+
+// SchemaElem sets the schema namespace of a table. It implements the
+// TableModifier interface, so it can be given to the Table constructor
+// along with the table's columns and constraints.
+type SchemaElem string
+
+var _ TableModifier = SchemaElem("")
+
+// Modify implements the TableModifier interface. It sets the schema of the
+// given table.
+func (schema SchemaElem) Modify(t *TableElem) error {
+	if t == nil {
+		return fmt.Errorf("aspect: schemas cannot modify a nil table")
+	}
+	if schema == "" {
+		return fmt.Errorf("aspect: schema names cannot be blank")
+	}
+	if t.schema != "" {
+		return fmt.Errorf(
+			"aspect: table '%s' already belongs to the schema '%s'",
+			t.name,
+			t.schema,
+		)
+	}
+	t.schema = string(schema)
+	return nil
+}
+
+// Schema creates a SchemaElem that will place its table in the schema with
+// the given name.
+//  var logs = aspect.Table("logs",
+//      aspect.Schema("audit"),
+//      aspect.Column("id", aspect.Integer{PrimaryKey: true}),
+//  )
+func Schema(name string) SchemaElem {
+	return SchemaElem(name)
+}
@@ -25,6 +25,16 @@ type SelectStmt struct {
 	offset     int
 }
 
+// Tables returns the tables selected from, followed by any joined tables
+func (stmt SelectStmt) Tables() []*TableElem {
+	tables := make([]*TableElem, len(stmt.tables), len(stmt.tables)+len(stmt.join))
+	copy(tables, stmt.tables)
+	for _, join := range stmt.join {
+		tables = append(tables, join.table)
+	}
+	return tables
+}
+
 // TableExists checks if a table already exists in the SELECT statement.
 func (stmt SelectStmt) TableExists(name string) bool {
 	for _, table := range stmt.tables {
@@ -51,6 +61,37 @@ func (stmt SelectStmt) CompileColumns(d Dialect, params *Parameters) []string {
 	return names
 }
 
+// resultNames returns the names of the selected columns qualified by their
+// tables, such as users.id, which allows results to be aligned to fields
+// with table qualified tags. Aliased columns are named by their alias.
+func (stmt SelectStmt) resultNames() []string {
+	names := make([]string, len(stmt.columns))
+	for i, column := range stmt.columns {
+		if column.alias != "" {
+			names[i] = column.alias
+		} else if column.table != nil {
+			names[i] = column.table.Name() + "." + column.name
+		} else {
+			names[i] = column.name
+		}
+	}
+	return names
+}
+
+// resultKey returns the index of the first selected column that is the sole
+// primary key of its table, or zero if there are none.
+func (stmt SelectStmt) resultKey() int {
+	for i, column := range stmt.columns {
+		if column.alias != "" || column.table == nil {
+			continue
+		}
+		if pk := column.table.PrimaryKey(); len(pk) == 1 && pk[0] == column.name {
+			return i
+		}
+	}
+	return 0
+}
+
 // String outputs the parameter-less SELECT statement in a neutral dialect.
 func (stmt SelectStmt) String() string {
 	compiled, _ := stmt.Compile(&defaultDialect{}, Params())
@@ -1,6 +1,10 @@
 package aspect
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestSelect(t *testing.T) {
 	expect := NewTester(t, &defaultDialect{})
@@ -98,3 +102,15 @@ func TestSelectTable(t *testing.T) {
 		),
 	)
 }
+
+func TestSelect_resultNames(t *testing.T) {
+	stmt := Select(
+		users.C["id"],
+		users.C["name"].As("username"),
+		parents.C["id"].As("parents.id"),
+	)
+	assert.Equal(t,
+		[]string{"users.id", "username", "parents.id"},
+		stmt.resultNames(),
+	)
+}
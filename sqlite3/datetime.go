@@ -9,15 +9,16 @@ import (
 
 // CurrentTimestamp is a default option for sqlite3 column types, but it
 // only has second resolution
-const CurrentTimestamp = `CURRENT_TIMESTAMP`
+var CurrentTimestamp = aspect.Now()
 
 type Datetime struct {
 	PrimaryKey bool
 	NotNull    bool
-	Default    string // TODO Should this be a clause that compiles?
+	Default    interface{} // A literal value or a Clause
 }
 
 var _ aspect.Type = Datetime{}
+var _ aspect.Defaulter = Datetime{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s Datetime) Create(d aspect.Dialect) (string, error) {
@@ -25,18 +26,27 @@ func (s Datetime) Create(d aspect.Dialect) (string, error) {
 	if s.NotNull {
 		compiled += " NOT NULL"
 	}
-	if s.Default != "" {
-		compiled += fmt.Sprintf(" DEFAULT %s", s.Default)
+	if aspect.HasDefaultValue(s.Default) {
+		def, err := aspect.CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		compiled += fmt.Sprintf(" %s", def)
 	}
 	return compiled, nil
 }
 
+// HasDefault implements the aspect.Defaulter interface.
+func (s Datetime) HasDefault() bool {
+	return aspect.HasDefaultValue(s.Default)
+}
+
 func (s Datetime) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s Datetime) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s Datetime) IsUnique() bool {
@@ -14,7 +14,7 @@ func TestDatetime(t *testing.T) {
 
 	expect.Create("DATETIME", Datetime{})
 	expect.Create(
-		"DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		"DATETIME NOT NULL DEFAULT (CURRENT_TIMESTAMP)",
 		Datetime{NotNull: true, Default: CurrentTimestamp},
 	)
 
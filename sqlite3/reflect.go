@@ -0,0 +1,215 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aodin/aspect"
+)
+
+var _ aspect.Reflector = &Sqlite3{}
+
+type tableInfoRow struct {
+	Name       string         `db:"name"`
+	Type       string         `db:"type"`
+	NotNull    bool           `db:"notnull"`
+	Default    sql.NullString `db:"dflt_value"`
+	PrimaryKey int            `db:"pk"`
+}
+
+type indexListRow struct {
+	Name   string `db:"name"`
+	Unique bool   `db:"unique"`
+	Origin string `db:"origin"`
+}
+
+type indexInfoRow struct {
+	SeqNo int    `db:"seqno"`
+	Name  string `db:"name"`
+}
+
+type foreignKeyRow struct {
+	ID       int            `db:"id"`
+	Seq      int            `db:"seq"`
+	Table    string         `db:"table"`
+	From     string         `db:"from"`
+	To       sql.NullString `db:"to"`
+	OnUpdate string         `db:"on_update"`
+	OnDelete string         `db:"on_delete"`
+}
+
+// TableNames returns the names of all tables in the database, excluding
+// sqlite's internal tables.
+func (d *Sqlite3) TableNames(conn aspect.Connection) ([]string, error) {
+	var names []string
+	err := query(conn, &names,
+		`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+	)
+	return names, err
+}
+
+// ReflectTable describes the table with the given name using sqlite's
+// table_info, index_list and foreign_key_list pragmas.
+func (d *Sqlite3) ReflectTable(conn aspect.Connection, name string) (aspect.TableInfo, error) {
+	info := aspect.TableInfo{Name: name}
+
+	var columns []tableInfoRow
+	if err := pragma(conn, &columns, "table_info", name); err != nil {
+		return info, err
+	}
+	pks := make(map[int]string)
+	for _, column := range columns {
+		info.Columns = append(info.Columns, aspect.ColumnInfo{
+			Name: column.Name,
+			Type: parseType(column),
+		})
+		if column.PrimaryKey > 0 {
+			pks[column.PrimaryKey] = column.Name
+		}
+	}
+	for i := 1; i <= len(pks); i++ {
+		info.PrimaryKey = append(info.PrimaryKey, pks[i])
+	}
+
+	// Only indexes created by UNIQUE constraints are reflected
+	var indexes []indexListRow
+	if err := pragma(conn, &indexes, "index_list", name); err != nil {
+		return info, err
+	}
+	for _, index := range indexes {
+		if !index.Unique || index.Origin != "u" {
+			continue
+		}
+		var names []indexInfoRow
+		if err := pragma(conn, &names, "index_info", index.Name); err != nil {
+			return info, err
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return names[i].SeqNo < names[j].SeqNo
+		})
+		unique := make([]string, len(names))
+		for i, n := range names {
+			unique[i] = n.Name
+		}
+		info.Uniques = append(info.Uniques, unique)
+	}
+	// Sort the unique constraints for a deterministic output
+	sort.Slice(info.Uniques, func(i, j int) bool {
+		return strings.Join(info.Uniques[i], ",") < strings.Join(info.Uniques[j], ",")
+	})
+
+	var fks []foreignKeyRow
+	if err := pragma(conn, &fks, "foreign_key_list", name); err != nil {
+		return info, err
+	}
+	counts := make(map[int]int)
+	for _, fk := range fks {
+		counts[fk.ID]++
+	}
+	for _, fk := range fks {
+		// Multi-column foreign keys are not supported
+		if counts[fk.ID] > 1 {
+			continue
+		}
+		ref := fk.To.String
+		if !fk.To.Valid || ref == "" {
+			// The foreign key implicitly references the primary key
+			pk, err := primaryKey(conn, fk.Table)
+			if err != nil {
+				return info, err
+			}
+			if len(pk) != 1 {
+				continue
+			}
+			ref = pk[0]
+		}
+		info.ForeignKeys = append(info.ForeignKeys, aspect.ForeignKeyInfo{
+			Column:    fk.From,
+			RefTable:  fk.Table,
+			RefColumn: ref,
+			OnDelete:  aspect.ParseAction(fk.OnDelete),
+			OnUpdate:  aspect.ParseAction(fk.OnUpdate),
+		})
+	}
+	return info, nil
+}
+
+// primaryKey returns the ordered primary key columns of the given table
+func primaryKey(conn aspect.Connection, name string) ([]string, error) {
+	var columns []tableInfoRow
+	if err := pragma(conn, &columns, "table_info", name); err != nil {
+		return nil, err
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].PrimaryKey < columns[j].PrimaryKey
+	})
+	var pk []string
+	for _, column := range columns {
+		if column.PrimaryKey > 0 {
+			pk = append(pk, column.Name)
+		}
+	}
+	return pk, nil
+}
+
+// parseType converts the declared type of a column to an aspect type.
+// Unrecognized types are returned as an aspect.RawType.
+func parseType(column tableInfoRow) aspect.Type {
+	var def interface{}
+	if column.Default.Valid {
+		def = aspect.Raw(column.Default.String)
+	}
+	notNull := column.NotNull
+
+	declared := strings.ToUpper(strings.TrimSpace(column.Type))
+	base, length := declared, 0
+	if i := strings.Index(declared, "("); i != -1 {
+		base = strings.TrimSpace(declared[:i])
+		args := strings.TrimSuffix(declared[i+1:], ")")
+		length, _ = strconv.Atoi(strings.TrimSpace(args))
+	}
+
+	switch base {
+	case "INTEGER", "INT", "SMALLINT":
+		return aspect.Integer{NotNull: notNull, Default: def}
+	case "BIGINT":
+		return aspect.BigInt{NotNull: notNull, Default: def}
+	case "VARCHAR", "CHARACTER VARYING":
+		return aspect.String{Length: length, NotNull: notNull, Default: def}
+	case "TEXT":
+		return aspect.Text{NotNull: notNull, Default: def}
+	case "BOOLEAN", "BOOL":
+		return aspect.Boolean{NotNull: notNull, Default: def}
+	case "TIMESTAMP":
+		return aspect.Timestamp{NotNull: notNull, Default: def}
+	case "DATETIME":
+		return Datetime{NotNull: notNull, Default: def}
+	case "DATE":
+		return aspect.Date{NotNull: notNull, Default: def}
+	case "REAL":
+		return aspect.Real{NotNull: notNull, Default: def}
+	case "DOUBLE", "DOUBLE PRECISION":
+		return aspect.Double{NotNull: notNull, Default: def}
+	}
+	return aspect.RawType{Name: declared, NotNull: notNull, Default: def}
+}
+
+// pragma queries the given pragma function for the given table or index
+func pragma(conn aspect.Connection, dest interface{}, function, name string) error {
+	return query(conn, dest, fmt.Sprintf(
+		`PRAGMA %s("%s")`, function, strings.Replace(name, `"`, `""`, -1),
+	))
+}
+
+// query executes the given raw SQL and scans all results into dest
+func query(conn aspect.Connection, dest interface{}, stmt string, args ...interface{}) error {
+	result, err := conn.Query(aspect.Raw(stmt), args...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	return result.All(dest)
+}
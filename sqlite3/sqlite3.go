@@ -1,7 +1,10 @@
 package sqlite3
 
 import (
-	_ "github.com/mattn/go-sqlite3"
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
 
 	"github.com/aodin/aspect"
 )
@@ -9,11 +12,79 @@ import (
 // Sqlite3 implements the Dialect interface for sqlite3 databases.
 type Sqlite3 struct{}
 
+// Name returns the name the dialect is registered with.
+func (d *Sqlite3) Name() string {
+	return "sqlite3"
+}
+
 // Parameterize returns the sqlite3 specific parameterization scheme.
 func (d *Sqlite3) Parameterize(i int) string {
 	return `?`
 }
 
+// Retryable returns true if the database was busy or locked by another
+// connection
+func (d *Sqlite3) Retryable(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// CanAlterColumn returns false, since sqlite3 can only add and drop the
+// columns of existing tables
+func (d *Sqlite3) CanAlterColumn(alteration aspect.ColumnAlteration) bool {
+	return false
+}
+
+// TranslateError translates constraint violations into the typed errors of
+// aspect. Table and column names are parsed from the error message, such
+// as: UNIQUE constraint failed: users.email
+func (d *Sqlite3) TranslateError(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.Code != sqlite3.ErrConstraint {
+		return err
+	}
+	violation := aspect.ConstraintError{Err: err}
+	var failed string
+	if i := strings.Index(sqliteErr.Error(), "constraint failed: "); i != -1 {
+		failed = sqliteErr.Error()[i+len("constraint failed: "):]
+	}
+
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		violation.Table, violation.Columns = failedColumns(failed)
+		return &aspect.UniqueViolation{ConstraintError: violation}
+	case sqlite3.ErrConstraintNotNull:
+		violation.Table, violation.Columns = failedColumns(failed)
+		return &aspect.NotNullViolation{ConstraintError: violation}
+	case sqlite3.ErrConstraintForeignKey:
+		return &aspect.ForeignKeyViolation{ConstraintError: violation}
+	case sqlite3.ErrConstraintCheck:
+		// The constraint is named by either its name or its expression
+		violation.Constraint = failed
+		return &aspect.CheckViolation{ConstraintError: violation}
+	}
+	return err
+}
+
+// failedColumns parses the table and column names of a list of qualified
+// columns, such as: users.first_name, users.last_name
+func failedColumns(failed string) (table string, columns []string) {
+	if failed == "" {
+		return
+	}
+	for _, qualified := range strings.Split(failed, ", ") {
+		if i := strings.LastIndex(qualified, "."); i != -1 {
+			table = qualified[:i]
+			qualified = qualified[i+1:]
+		}
+		columns = append(columns, qualified)
+	}
+	return
+}
+
 // Add the sqlite3 dialect to the dialect registry
 func init() {
 	aspect.RegisterDialect("sqlite3", &Sqlite3{})
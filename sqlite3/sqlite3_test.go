@@ -1,9 +1,14 @@
 package sqlite3
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -12,6 +17,10 @@ import (
 
 // The sql dialect must implement the dialect interface
 var _ aspect.Dialect = &Sqlite3{}
+var _ aspect.Namer = &Sqlite3{}
+var _ aspect.Retrier = &Sqlite3{}
+var _ aspect.ErrorTranslator = &Sqlite3{}
+var _ aspect.ColumnAlterer = &Sqlite3{}
 
 var things = aspect.Table("things",
 	aspect.Column("name", aspect.String{Length: 32, NotNull: true}),
@@ -237,3 +246,802 @@ func TestResultTypes(t *testing.T) {
 	assert.Equal(t, "admin", embed.fullname.Name)
 	assert.Equal(t, "secret", embed.Password)
 }
+
+func TestMetaData(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	meta := aspect.NewMetaData()
+	posts := aspect.Table("posts",
+		meta,
+		aspect.ForeignKey("user_id", users.C["id"], aspect.Integer{}),
+		aspect.Column("title", aspect.String{}),
+	)
+	require.Nil(t, meta.Add(users))
+
+	// Tables should be created in dependency order
+	require.Nil(t, meta.CreateAll(conn))
+	conn.MustExecute(users.Insert().Values(user{ID: 1, Name: "admin"}))
+	conn.MustExecute(posts.Insert().Values(
+		aspect.Values{"user_id": 1, "title": "hello"},
+	))
+
+	// Creating existing tables should not error
+	require.Nil(t, meta.CreateAll(conn))
+
+	// Tables should be dropped in reverse dependency order
+	require.Nil(t, meta.DropAll(conn))
+	require.Nil(t, meta.DropAll(conn))
+}
+
+func TestReflect(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(aspect.Raw(`CREATE TABLE "authors" (
+  "id" INTEGER NOT NULL,
+  "email" VARCHAR(128) NOT NULL,
+  "joined" DATETIME DEFAULT CURRENT_TIMESTAMP,
+  "rating" NUMERIC(4, 2),
+  "mentor_id" INTEGER REFERENCES "authors"("id"),
+  PRIMARY KEY ("id"),
+  UNIQUE ("email")
+);`))
+	conn.MustExecute(aspect.Raw(`CREATE TABLE "books" (
+  "author_id" INTEGER NOT NULL REFERENCES "authors" ON DELETE CASCADE,
+  "title" TEXT NOT NULL
+);`))
+
+	books, err := aspect.Reflect(conn, "books")
+	require.Nil(t, err)
+	columns := books.Columns()
+	require.Equal(t, 2, len(columns))
+	assert.Equal(t, "author_id", columns[0].Name())
+	assert.Equal(t, "title", columns[1].Name())
+
+	fks := books.ForeignKeys()
+	require.Equal(t, 1, len(fks))
+	assert.Equal(t, "authors", fks[0].ReferencesTable().Name())
+
+	expect := aspect.NewTester(t, &Sqlite3{})
+	expect.SQL(
+		`CREATE TABLE "authors" (
+  "id" INTEGER NOT NULL,
+  "email" VARCHAR(128) NOT NULL,
+  "joined" DATETIME DEFAULT (CURRENT_TIMESTAMP),
+  "rating" NUMERIC(4, 2),
+  "mentor_id" INTEGER REFERENCES authors("id"),
+  PRIMARY KEY ("id"),
+  UNIQUE ("email")
+);`,
+		fks[0].ReferencesTable().Create(),
+	)
+	expect.SQL(
+		`CREATE TABLE "books" (
+  "author_id" INTEGER NOT NULL REFERENCES authors("id") ON DELETE CASCADE,
+  "title" TEXT NOT NULL
+);`,
+		books.Create(),
+	)
+
+	// Reflected tables can be used with the query builders
+	conn.MustExecute(fks[0].ReferencesTable().Insert().Values(
+		aspect.Values{"id": 1, "email": "admin@example.com"},
+	))
+	conn.MustExecute(books.Insert().Values(
+		aspect.Values{"author_id": 1, "title": "Aspect"},
+	))
+	var title string
+	conn.MustQueryOne(aspect.Select(books.C["title"]), &title)
+	assert.Equal(t, "Aspect", title)
+
+	meta, err := aspect.ReflectAll(conn)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(meta.Tables()))
+	sorted, err := meta.Sorted()
+	require.Nil(t, err)
+	assert.Equal(t, "authors", sorted[0].Name())
+	assert.Equal(t, "books", sorted[1].Name())
+
+	_, err = aspect.Reflect(conn, "missing")
+	assert.NotNil(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(aspect.Raw(`CREATE TABLE "accounts" (
+  "id" INTEGER NOT NULL,
+  "legacy" TEXT,
+  PRIMARY KEY ("id")
+);`))
+
+	declared := aspect.NewMetaData()
+	accounts := aspect.Table("accounts",
+		declared,
+		aspect.Column("id", aspect.Integer{NotNull: true}),
+		aspect.Column("email", aspect.String{Length: 128}),
+		aspect.Column(
+			"created_at", Datetime{NotNull: true, Default: CurrentTimestamp},
+		),
+		aspect.PrimaryKey("id"),
+	)
+	aspect.Table("sessions",
+		declared,
+		aspect.ForeignKey("account_id", accounts.C["id"], aspect.Integer{}),
+	)
+
+	reflected, err := aspect.ReflectAll(conn)
+	require.Nil(t, err)
+
+	changes, err := aspect.Diff(reflected, declared, &Sqlite3{})
+	require.Nil(t, err)
+	require.Equal(t, 4, len(changes.Up))
+	for _, stmt := range changes.Up {
+		conn.MustExecute(stmt)
+	}
+
+	// The reflected schema should now match the declared schema
+	reflected, err = aspect.ReflectAll(conn)
+	require.Nil(t, err)
+	changes, err = aspect.Diff(reflected, declared, &Sqlite3{})
+	require.Nil(t, err)
+	assert.True(t, changes.Empty())
+}
+
+func TestDiff_AlterColumn(t *testing.T) {
+	previous := aspect.NewMetaData()
+	aspect.Table("teams",
+		previous,
+		aspect.Column("id", aspect.Integer{PrimaryKey: true}),
+		aspect.Column("name", aspect.String{}),
+	)
+
+	// sqlite3 can add and drop columns
+	added := aspect.NewMetaData()
+	aspect.Table("teams",
+		added,
+		aspect.Column("id", aspect.Integer{PrimaryKey: true}),
+		aspect.Column("name", aspect.String{}),
+		aspect.Column("founded", aspect.Integer{}),
+	)
+	changes, err := aspect.Diff(previous, added, &Sqlite3{})
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(changes.Up))
+	assert.Equal(t, 1, len(changes.Down))
+
+	// But it cannot alter the type, NOT NULL or DEFAULT of a column
+	targets := []aspect.Type{
+		aspect.Text{},
+		aspect.String{NotNull: true},
+		aspect.String{Default: "a"},
+	}
+	for _, typ := range targets {
+		target := aspect.NewMetaData()
+		aspect.Table("teams",
+			target,
+			aspect.Column("id", aspect.Integer{PrimaryKey: true}),
+			aspect.Column("name", typ),
+		)
+		_, err = aspect.Diff(previous, target, &Sqlite3{})
+		assert.NotNil(t, err, "altering the name to %#v should error", typ)
+	}
+
+	teams := previous.Table("teams")
+	_, err = teams.Alter().SetNotNull("name").Compile(&Sqlite3{}, aspect.Params())
+	assert.NotNil(t, err)
+}
+
+var addresses = aspect.Table("addresses",
+	aspect.Column("id", aspect.Integer{NotNull: true}),
+	aspect.ForeignKey("user_id", users.C["id"], aspect.Integer{}),
+	aspect.Column("city", aspect.String{NotNull: true}),
+	aspect.PrimaryKey("id"),
+)
+
+type address struct {
+	ID     int64         `db:"addresses.id"`
+	UserID sql.NullInt64 `db:"user_id"`
+	City   string        `db:"city"`
+}
+
+type userAddress struct {
+	ID      int64  `db:"users.id"`
+	Name    string `db:"name"`
+	Address *address
+}
+
+func TestJoinedResults(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(users.Create())
+	conn.MustExecute(addresses.Create())
+	conn.MustExecute(users.Insert().Values([]user{
+		{ID: 1, Name: "admin", Password: "secret"},
+		{ID: 2, Name: "client", Password: "secret"},
+	}))
+	conn.MustExecute(addresses.Insert().Values(aspect.Values{
+		"id": 10, "user_id": 1, "city": "Denver",
+	}))
+
+	// Columns of the same name are aligned by their table and users
+	// without an address are given a nil pointer
+	stmt := users.Select(
+		addresses.C["id"], addresses.C["city"],
+	).LeftOuterJoinOn(
+		addresses, users.C["id"].Equals(addresses.C["user_id"]),
+	).OrderBy(users.C["id"])
+
+	var results []userAddress
+	require.Nil(t, conn.QueryAll(stmt, &results))
+	require.Equal(t, 2, len(results))
+	assert.EqualValues(t, 1, results[0].ID)
+	require.NotNil(t, results[0].Address)
+	assert.EqualValues(t, 10, results[0].Address.ID)
+	assert.Equal(t, "Denver", results[0].Address.City)
+	assert.EqualValues(t, 2, results[1].ID)
+	assert.Nil(t, results[1].Address)
+
+	// Existing pointers are reset for NULL rows
+	existing := userAddress{Address: &address{City: "Boulder"}}
+	require.Nil(t, conn.QueryOne(
+		stmt.Where(users.C["id"].Equals(2)), &existing,
+	))
+	assert.Equal(t, "client", existing.Name)
+	assert.Nil(t, existing.Address)
+}
+
+type userWithAddresses struct {
+	ID        int64      `db:"id"`
+	Name      string     `db:"name"`
+	Addresses []*address `db:"-"`
+}
+
+type addressWithUser struct {
+	ID     int64         `db:"id"`
+	UserID sql.NullInt64 `db:"user_id"`
+	City   string        `db:"city"`
+	User   user          `db:"-"`
+}
+
+func TestRelations(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(users.Create())
+	conn.MustExecute(addresses.Create())
+	conn.MustExecute(users.Insert().Values([]user{
+		{ID: 1, Name: "admin", Password: "secret"},
+		{ID: 2, Name: "client", Password: "secret"},
+	}))
+	conn.MustExecute(addresses.Insert().Values([]aspect.Values{
+		{"id": 10, "user_id": 1, "city": "Denver"},
+		{"id": 11, "user_id": 1, "city": "Boulder"},
+		{"id": 12, "user_id": nil, "city": "Golden"},
+	}))
+
+	var owners []userWithAddresses
+	conn.MustQueryAll(users.Select().OrderBy(users.C["id"]), &owners)
+	userAddresses := aspect.HasMany(addresses.C["user_id"], "Addresses")
+	require.Nil(t, userAddresses.OrderBy(addresses.C["city"]).Load(conn, &owners))
+	require.Equal(t, 2, len(owners[0].Addresses))
+	assert.Equal(t, "Boulder", owners[0].Addresses[0].City)
+	assert.Equal(t, "Denver", owners[0].Addresses[1].City)
+	assert.Equal(t, 0, len(owners[1].Addresses))
+
+	var located []addressWithUser
+	conn.MustQueryAll(addresses.Select().OrderBy(addresses.C["id"]), &located)
+	addressUser := aspect.BelongsTo(addresses.C["user_id"], "User")
+	require.Nil(t, addressUser.Load(conn, &located))
+	assert.Equal(t, "admin", located[0].User.Name)
+	assert.Equal(t, "admin", located[1].User.Name)
+	assert.Equal(t, user{}, located[2].User)
+
+	// Single structs can also be loaded
+	owner := userWithAddresses{ID: 2}
+	require.Nil(t, userAddresses.Load(conn, &owner))
+	assert.NotNil(t, owner.Addresses)
+
+	// Keys are queried in batches and the results merged
+	defer func(size int) { aspect.RelationBatchSize = size }(aspect.RelationBatchSize)
+	aspect.RelationBatchSize = 1
+	var queries int
+	counted := conn.WithHook(func(aspect.Event) { queries += 1 })
+
+	conn.MustExecute(users.Insert().Values(user{ID: 3, Name: "daemon"}))
+	conn.MustExecute(addresses.Update().Values(
+		aspect.Values{"user_id": 3},
+	).Where(addresses.C["id"].Equals(12)))
+	located = nil
+	conn.MustQueryAll(addresses.Select().OrderBy(addresses.C["id"]), &located)
+	require.Nil(t, addressUser.Load(counted, &located))
+	assert.Equal(t, 2, queries)
+	assert.Equal(t, "admin", located[0].User.Name)
+	assert.Equal(t, "admin", located[1].User.Name)
+	assert.Equal(t, "daemon", located[2].User.Name)
+
+	owners = nil
+	conn.MustQueryAll(users.Select().OrderBy(users.C["id"]), &owners)
+	queries = 0
+	require.Nil(t, userAddresses.OrderBy(addresses.C["city"]).Load(counted, &owners))
+	assert.Equal(t, 3, queries)
+	require.Equal(t, 2, len(owners[0].Addresses))
+	assert.Equal(t, "Boulder", owners[0].Addresses[0].City)
+	assert.Equal(t, 0, len(owners[1].Addresses))
+	require.Equal(t, 1, len(owners[2].Addresses))
+	assert.Equal(t, "Golden", owners[2].Addresses[0].City)
+}
+
+type addressWithOwner struct {
+	ID     int64         `db:"id"`
+	UserID sql.NullInt64 `db:"user_id"`
+	City   string        `db:"city"`
+	Owner  *user         `db:"-"`
+}
+
+func TestRelations_Batches(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(users.Create())
+	conn.MustExecute(addresses.Create())
+
+	// Give every user an address, with more users than a single batch
+	n := 2*aspect.RelationBatchSize + 1
+	tx := conn.MustBegin()
+	for i := 1; i <= n; i++ {
+		tx.MustExecute(users.Insert().Values(
+			user{ID: int64(i), Name: fmt.Sprintf("user%d", i)},
+		))
+		tx.MustExecute(addresses.Insert().Values(aspect.Values{
+			"id": i, "user_id": i, "city": fmt.Sprintf("city%d", i),
+		}))
+	}
+	require.Nil(t, tx.Commit())
+
+	var queries int
+	counted := conn.WithHook(func(aspect.Event) { queries += 1 })
+
+	var owners []userWithAddresses
+	conn.MustQueryAll(users.Select().OrderBy(users.C["id"]), &owners)
+	require.Equal(t, n, len(owners))
+	userAddresses := aspect.HasMany(addresses.C["user_id"], "Addresses")
+	require.Nil(t, userAddresses.Load(counted, &owners))
+	assert.Equal(t, 3, queries)
+	for _, owner := range owners {
+		require.Equal(t, 1, len(owner.Addresses))
+		assert.Equal(t, fmt.Sprintf("city%d", owner.ID), owner.Addresses[0].City)
+	}
+
+	var located []addressWithOwner
+	conn.MustQueryAll(addresses.Select().OrderBy(addresses.C["id"]), &located)
+	queries = 0
+	require.Nil(t, aspect.BelongsTo(addresses.C["user_id"], "Owner").Load(
+		counted, &located,
+	))
+	assert.Equal(t, 3, queries)
+	for _, row := range located {
+		require.NotNil(t, row.Owner)
+		assert.Equal(t, row.UserID.Int64, row.Owner.ID)
+	}
+}
+
+func TestRelations_Empty(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(users.Create())
+	conn.MustExecute(addresses.Create())
+	conn.MustExecute(users.Insert().Values(
+		user{ID: 1, Name: "admin", Password: "secret"},
+	))
+	conn.MustExecute(addresses.Insert().Values([]aspect.Values{
+		{"id": 10, "user_id": 1, "city": "Denver"},
+		{"id": 11, "user_id": nil, "city": "Golden"},
+		{"id": 12, "user_id": 2, "city": "Boulder"},
+	}))
+
+	var queries int
+	counted := conn.WithHook(func(aspect.Event) { queries += 1 })
+
+	// Empty parent slices, and slices of only nil pointers, do not query
+	userAddresses := aspect.HasMany(addresses.C["user_id"], "Addresses")
+	var owners []userWithAddresses
+	require.Nil(t, userAddresses.Load(counted, &owners))
+	assert.Equal(t, 0, len(owners))
+	missing := []*userWithAddresses{nil, nil}
+	require.Nil(t, userAddresses.Load(counted, &missing))
+	assert.Equal(t, 0, queries)
+
+	// Targets of NULL keys and keys without a row are left nil, and
+	// existing targets are reset
+	var located []addressWithOwner
+	conn.MustQueryAll(addresses.Select().OrderBy(addresses.C["id"]), &located)
+	located[1].Owner = &user{Name: "stale"}
+	located[2].Owner = &user{Name: "stale"}
+	addressOwner := aspect.BelongsTo(addresses.C["user_id"], "Owner")
+	require.Nil(t, addressOwner.Load(conn, &located))
+	require.NotNil(t, located[0].Owner)
+	assert.Equal(t, "admin", located[0].Owner.Name)
+	assert.Nil(t, located[1].Owner)
+	assert.Nil(t, located[2].Owner)
+
+	// Nil elements of a slice of pointers are skipped
+	partial := []*addressWithOwner{
+		nil, {ID: 10, UserID: sql.NullInt64{Int64: 1, Valid: true}},
+	}
+	require.Nil(t, addressOwner.Load(conn, &partial))
+	assert.Nil(t, partial[0])
+	require.NotNil(t, partial[1].Owner)
+	assert.Equal(t, "admin", partial[1].Owner.Name)
+}
+
+func TestResultShapes(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(users.Create())
+	conn.MustExecute(users.Insert().Values([]user{
+		{ID: 1, Name: "admin", Password: "secret"},
+		{ID: 2, Name: "client", Password: "hunter2"},
+	}))
+
+	// Structs are keyed by the primary key, wherever it is selected
+	var byID map[int64]user
+	require.Nil(t, conn.QueryAll(
+		aspect.Select(users.C["name"], users.C["id"]), &byID,
+	))
+	require.Equal(t, 2, len(byID))
+	assert.Equal(t, "admin", byID[1].Name)
+	assert.Equal(t, "client", byID[2].Name)
+
+	var ptrs map[int]*user
+	require.Nil(t, conn.QueryAll(users.Select(), &ptrs))
+	require.NotNil(t, ptrs[2])
+	assert.Equal(t, "hunter2", ptrs[2].Password)
+
+	// Two column results can be scanned into maps of native types
+	var passwords map[string]string
+	require.Nil(t, conn.QueryAll(
+		aspect.Select(users.C["name"], users.C["password"]), &passwords,
+	))
+	assert.Equal(t, map[string]string{"admin": "secret", "client": "hunter2"}, passwords)
+
+	// Or by column
+	var columns struct {
+		IDs   []int64  `db:"id"`
+		Names []string `db:"name"`
+	}
+	require.Nil(t, conn.QueryAll(
+		users.Select().OrderBy(users.C["id"]), &columns,
+	))
+	assert.Equal(t, []int64{1, 2}, columns.IDs)
+	assert.Equal(t, []string{"admin", "client"}, columns.Names)
+}
+
+type preferences struct {
+	Theme  string `json:"theme"`
+	Alerts bool   `json:"alerts"`
+}
+
+type level int
+
+const (
+	beginner level = iota
+	expert
+)
+
+func (l level) MarshalText() ([]byte, error) {
+	switch l {
+	case beginner:
+		return []byte("beginner"), nil
+	case expert:
+		return []byte("expert"), nil
+	}
+	return nil, fmt.Errorf("unknown level %d", l)
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "beginner":
+		*l = beginner
+	case "expert":
+		*l = expert
+	default:
+		return fmt.Errorf("unknown level %s", text)
+	}
+	return nil
+}
+
+var profiles = aspect.Table("profiles",
+	aspect.Column("id", aspect.Integer{NotNull: true}),
+	aspect.Column("preferences", aspect.Text{}),
+	aspect.Column("level", aspect.String{}),
+	aspect.PrimaryKey("id"),
+)
+
+type profile struct {
+	ID          int64        `db:"id"`
+	Preferences *preferences `db:"preferences"`
+	Level       level        `db:"level"`
+}
+
+func TestConverters(t *testing.T) {
+	db, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer db.Close()
+
+	// Structs are converted for every connection
+	aspect.RegisterConverter(preferences{}, aspect.JSONConverter{})
+	conn := db.WithConverter(level(0), aspect.TextConverter{})
+
+	conn.MustExecute(profiles.Create())
+	conn.MustExecute(profiles.Insert().Values([]profile{
+		{ID: 1, Preferences: &preferences{Theme: "dark"}, Level: expert},
+		{ID: 2},
+	}))
+
+	var levels []string
+	require.Nil(t, conn.QueryAll(
+		aspect.Select(profiles.C["level"]).OrderBy(profiles.C["id"]), &levels,
+	))
+	assert.Equal(t, []string{"expert", "beginner"}, levels)
+
+	var results []profile
+	require.Nil(t, conn.QueryAll(
+		profiles.Select().OrderBy(profiles.C["id"]), &results,
+	))
+	require.Equal(t, 2, len(results))
+	require.NotNil(t, results[0].Preferences)
+	assert.Equal(t, "dark", results[0].Preferences.Theme)
+	assert.Equal(t, expert, results[0].Level)
+	assert.Nil(t, results[1].Preferences)
+	assert.Equal(t, beginner, results[1].Level)
+
+	// Parameters and updates are also converted
+	conn.MustExecute(profiles.Update().Values(aspect.Values{
+		"preferences": preferences{Alerts: true},
+	}).Where(profiles.C["level"].Equals(beginner)))
+
+	var updated profile
+	require.Nil(t, conn.QueryOne(
+		profiles.Select().Where(profiles.C["id"].Equals(2)), &updated,
+	))
+	require.NotNil(t, updated.Preferences)
+	assert.True(t, updated.Preferences.Alerts)
+
+	// Connections without the converter bind the underlying type
+	db.MustExecute(profiles.Update().Values(aspect.Values{
+		"level": expert,
+	}).Where(profiles.C["id"].Equals(2)))
+	var unconverted []string
+	require.Nil(t, db.QueryAll(
+		aspect.Select(profiles.C["level"]).OrderBy(profiles.C["id"]), &unconverted,
+	))
+	assert.Equal(t, []string{"expert", "1"}, unconverted)
+}
+
+func TestHooks(t *testing.T) {
+	db, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer db.Close()
+
+	var events []aspect.Event
+	conn := db.WithHook(func(event aspect.Event) {
+		events = append(events, event)
+	}).WithInterceptor(func(statement *aspect.Statement) error {
+		if _, ok := statement.Stmt.(aspect.DeleteStmt); ok {
+			return fmt.Errorf("deletes are not allowed")
+		}
+		return nil
+	})
+
+	conn.MustExecute(users.Create())
+	conn.MustExecute(users.Insert().Values([]user{
+		{ID: 1, Name: "admin", Password: "secret"},
+		{ID: 2, Name: "client", Password: "secret"},
+	}))
+	require.Equal(t, 2, len(events))
+	assert.EqualValues(t, 2, events[1].RowsAffected)
+	assert.Equal(t, 6, len(events[1].Args))
+
+	_, err = conn.Execute(users.Delete())
+	assert.Equal(t, "deletes are not allowed", err.Error())
+	require.Equal(t, 3, len(events))
+	assert.Equal(t, err, events[2].Err)
+
+	// Transactions inherit the hooks of their connection
+	tx := conn.MustBegin()
+	var names []string
+	require.Nil(t, tx.QueryAll(aspect.Select(users.C["name"]), &names))
+	assert.Equal(t, 2, len(names))
+	require.Equal(t, 4, len(events))
+	assert.True(t, events[3].Query)
+	assert.True(t, events[3].InTransaction)
+	require.Nil(t, tx.Rollback())
+
+	// The original connection has no hooks
+	db.MustExecute(users.Delete())
+	assert.Equal(t, 4, len(events))
+}
+
+func TestBind(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+
+	conn.MustExecute(users.Create())
+	insert := users.Insert().Values(aspect.Values{
+		"id":       aspect.Bind("id"),
+		"name":     aspect.Bind("name"),
+		"password": "secret",
+	})
+	conn.MustExecute(insert, aspect.Args{"id": 1, "name": "admin"})
+	conn.MustExecute(insert, aspect.Args{"id": 2, "name": "client"})
+	_, err = conn.Execute(insert, aspect.Args{"id": 3})
+	assert.NotNil(t, err)
+
+	stmt := aspect.Select(users.C["name"]).Where(
+		users.C["id"].GTE(aspect.Bind("min")),
+	).OrderBy(users.C["id"])
+	var names []string
+	result, err := conn.Query(stmt, aspect.Args{"min": 2})
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{2}, result.Args())
+	require.Nil(t, result.All(&names))
+	assert.Equal(t, []string{"client"}, names)
+
+	// Prepared statements can also be given named values
+	prepared, err := conn.Prepare(stmt)
+	require.Nil(t, err)
+	defer prepared.Close()
+	names = nil
+	require.Nil(t, prepared.QueryAll(&names, aspect.Args{"min": 1}))
+	assert.Equal(t, []string{"admin", "client"}, names)
+}
+
+func TestSavepoints(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+	conn.MustExecute(users.Create())
+
+	// Library code can begin transactions within transactions
+	insert := func(c aspect.Connection, u user, commit bool) {
+		tx := c.MustBegin()
+		tx.MustExecute(users.Insert().Values(u))
+		tx.MustCommitIf(&commit)
+	}
+
+	tx := conn.MustBegin()
+	insert(tx, user{ID: 1, Name: "admin"}, true)
+	insert(tx, user{ID: 2, Name: "client"}, false)
+
+	var names []string
+	require.Nil(t, tx.QueryAll(aspect.Select(users.C["name"]), &names))
+	assert.Equal(t, []string{"admin"}, names)
+
+	// Nested transactions do not end the outer transaction
+	nested := tx.MustBegin()
+	insert(nested, user{ID: 3, Name: "daemon"}, true)
+	require.Nil(t, nested.Rollback())
+	require.Nil(t, tx.Commit())
+
+	names = nil
+	require.Nil(t, conn.QueryAll(aspect.Select(users.C["name"]), &names))
+	assert.Equal(t, []string{"admin"}, names)
+}
+
+func TestFakeTx(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+	conn.MustExecute(users.Create())
+
+	tx := conn.MustBegin()
+	fake := aspect.FakeTx(tx)
+
+	// Transactions begun on the fake transaction are savepoints
+	nested, err := fake.Begin()
+	require.Nil(t, err)
+	nested.MustExecute(users.Insert().Values(user{ID: 1, Name: "admin"}))
+	require.Nil(t, nested.Commit())
+
+	nested, err = fake.Begin()
+	require.Nil(t, err)
+	nested.MustExecute(users.Insert().Values(user{ID: 2, Name: "client"}))
+	require.Nil(t, nested.Rollback())
+
+	// The fake transaction's own Commit and Rollback do nothing
+	require.Nil(t, fake.Commit())
+	require.Nil(t, fake.Rollback())
+
+	var names []string
+	require.Nil(t, fake.QueryAll(aspect.Select(users.C["name"]), &names))
+	assert.Equal(t, []string{"admin"}, names)
+
+	// Rolling back the real transaction removes everything
+	require.Nil(t, tx.Rollback())
+	names = nil
+	require.Nil(t, conn.QueryAll(aspect.Select(users.C["name"]), &names))
+	assert.Equal(t, 0, len(names))
+}
+
+func TestInTransaction(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+	conn.MustExecute(users.Create())
+
+	d := &Sqlite3{}
+	assert.True(t, d.Retryable(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	assert.False(t, d.Retryable(sqlite3.Error{Code: sqlite3.ErrConstraint}))
+
+	ctx := context.Background()
+	require.Nil(t, conn.InTransaction(ctx, nil, func(tx aspect.Transaction) error {
+		_, err := tx.Execute(users.Insert().Values(user{ID: 1, Name: "admin"}))
+		return err
+	}))
+	err = conn.InTransaction(ctx, nil, func(tx aspect.Transaction) error {
+		tx.MustExecute(users.Insert().Values(user{ID: 2, Name: "client"}))
+		return fmt.Errorf("abort")
+	})
+	assert.Equal(t, "abort", err.Error())
+
+	var names []string
+	require.Nil(t, conn.QueryAll(aspect.Select(users.C["name"]), &names))
+	assert.Equal(t, []string{"admin"}, names)
+}
+
+func TestTranslateError(t *testing.T) {
+	conn, err := aspect.Connect("sqlite3", ":memory:")
+	require.Nil(t, err, "Failed to connect to in-memory sqlite3 instance")
+	defer conn.Close()
+	conn.MustExecute(users.Create())
+	conn.MustExecute(users.Insert().Values(user{ID: 1, Name: "admin"}))
+
+	// Errors are not translated by default
+	_, err = conn.Execute(users.Insert().Values(user{ID: 1, Name: "client"}))
+	_, ok := err.(sqlite3.Error)
+	assert.True(t, ok, "error should be a sqlite3.Error, not %T", err)
+
+	conn = conn.TranslateErrors()
+	_, err = conn.Execute(users.Insert().Values(user{ID: 1, Name: "client"}))
+	var unique *aspect.UniqueViolation
+	require.True(t, errors.As(err, &unique), "error should be a UniqueViolation, not %T", err)
+	assert.Equal(t, "users", unique.Table)
+	assert.Equal(t, []string{"id"}, unique.Columns)
+	assert.Equal(t, users, unique.TableElem)
+
+	var sqliteErr sqlite3.Error
+	assert.True(t, errors.As(err, &sqliteErr), "the driver error should be unwrapped")
+
+	_, err = conn.Execute(users.Insert().Values(aspect.Values{
+		"id": 2, "name": nil, "password": "",
+	}))
+	var notNull *aspect.NotNullViolation
+	require.True(t, errors.As(err, &notNull), "error should be a NotNullViolation, not %T", err)
+	assert.Equal(t, []string{"name"}, notNull.Columns)
+	assert.Equal(t, users, notNull.TableElem)
+
+	d := &Sqlite3{}
+	check, ok := d.TranslateError(sqlite3.Error{
+		Code:         sqlite3.ErrConstraint,
+		ExtendedCode: sqlite3.ErrConstraintCheck,
+	}).(*aspect.CheckViolation)
+	require.True(t, ok)
+	assert.Equal(t, "", check.Constraint)
+
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	assert.Equal(t, busy, d.TranslateError(busy))
+}
@@ -17,10 +17,11 @@ type String struct {
 	NotNull    bool
 	Unique     bool
 	PrimaryKey bool
-	Default    *string
+	Default    interface{} // A literal value or a Clause
 }
 
 var _ Type = String{}
+var _ Defaulter = String{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s String) Create(d Dialect) (string, error) {
@@ -38,8 +39,12 @@ func (s String) Create(d Dialect) (string, error) {
 	if s.Unique {
 		attrs = append(attrs, "UNIQUE")
 	}
-	if s.Default != nil {
-		attrs = append(attrs, fmt.Sprintf("DEFAULT '%s'", *s.Default))
+	if HasDefaultValue(s.Default) {
+		def, err := CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		attrs = append(attrs, def)
 	}
 	if len(attrs) > 0 {
 		compiled += fmt.Sprintf(" %s", strings.Join(attrs, " "))
@@ -51,8 +56,13 @@ func (s String) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
+// HasDefault implements the Defaulter interface.
+func (s String) HasDefault() bool {
+	return HasDefaultValue(s.Default)
+}
+
 func (s String) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s String) IsUnique() bool {
@@ -17,6 +17,13 @@ func TestString(t *testing.T) {
 	)
 
 	expect.Create("VARCHAR DEFAULT ''", String{Default: Blank})
+	expect.Create(
+		"VARCHAR NOT NULL DEFAULT 'it''s'",
+		String{NotNull: true, Default: "it's"},
+	)
+	expect.Create("VARCHAR DEFAULT (LOWER('A'))", String{
+		Default: FuncClause{Inner: Literal("A"), F: "LOWER"},
+	})
 
 	// Test Type methods
 	value, err := String{}.Validate("HEY")
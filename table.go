@@ -28,6 +28,7 @@ func validateTableName(name string) error {
 // TODO make this an internal struct?
 type TableElem struct {
 	name    string
+	schema  string
 	C       ColumnSet
 	order   []string
 	pk      PrimaryKeyArray
@@ -41,6 +42,12 @@ func (table *TableElem) Name() string {
 	return table.name
 }
 
+// Schema returns the table's schema namespace. It will be blank if no
+// schema was specified.
+func (table *TableElem) Schema() string {
+	return table.schema
+}
+
 // AddCreatable adds a new Creatable to the table
 func (table *TableElem) AddCreatable(c Creatable) {
 	table.creates = append(table.creates, c)
@@ -67,10 +74,14 @@ func (table *TableElem) ForeignKeys() []ForeignKeyElem {
 }
 
 // Compile implements the Compiles interface allowing its use in statements.
+// If the table has a schema, the name will be qualified by the schema.
 // TODO Compile might not be the best name for this method, since it is
 // not a target for compilation
 func (table *TableElem) Compile(d Dialect, params *Parameters) string {
-	return fmt.Sprintf(`"%s"`, table.String())
+	if table.schema != "" {
+		return fmt.Sprintf(`"%s"."%s"`, table.schema, table.name)
+	}
+	return fmt.Sprintf(`"%s"`, table.name)
 }
 
 // Columns returns the table's columns in proper order.
@@ -87,9 +98,22 @@ func (table *TableElem) Create() CreateStmt {
 	return CreateStmt{table: table}
 }
 
-// Create generates the table's DROP statement.
+// Alter is an alias for AlterTable(table). It will generate an ALTER TABLE
+// statement for the table.
+func (table *TableElem) Alter() AlterTableStmt {
+	return AlterTable(table)
+}
+
+// Drop is an alias for Drop(table). It will generate the table's DROP
+// statement.
 func (table *TableElem) Drop() DropStmt {
-	return DropStmt{table: table}
+	return Drop(table)
+}
+
+// Truncate is an alias for Truncate(table). It will generate the table's
+// TRUNCATE statement.
+func (table *TableElem) Truncate() TruncateStmt {
+	return Truncate(table)
 }
 
 // Select is an alias for Select(table). It will generate a SELECT statement
@@ -139,21 +163,42 @@ func (table *TableElem) Update() UpdateStmt {
 // any number of columns and constraints that implement the TableModifier
 // interface.
 func Table(name string, elements ...TableModifier) *TableElem {
-	if err := validateTableName(name); err != nil {
+	// Panic on error since little can be done with a bad schema
+	table, err := newTable(name, elements...)
+	if err != nil {
 		log.Panic(err)
 	}
+	return table
+}
+
+// newTable constructs a TableElem, returning any error encountered while
+// applying its elements.
+func newTable(name string, elements ...TableModifier) (*TableElem, error) {
+	if err := validateTableName(name); err != nil {
+		return nil, err
+	}
 
 	table := &TableElem{
 		name: name,
 		C:    ColumnSet{},
 	}
 
-	// Pass the table to each element for potential modification
+	// Pass the table to each element for potential modification. MetaData
+	// registers the table only once every other element has succeeded.
+	var registries []*MetaData
 	for _, element := range elements {
-		// Panic on error since little can be done with a bad schema
+		if meta, ok := element.(*MetaData); ok {
+			registries = append(registries, meta)
+			continue
+		}
 		if err := element.Modify(table); err != nil {
-			log.Panic(err)
+			return nil, err
 		}
 	}
-	return table
+	for _, meta := range registries {
+		if err := meta.Add(table); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
 }
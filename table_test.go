@@ -53,6 +53,12 @@ var attrs = Table("attrs",
 	Unique("a", "b"),
 )
 
+var logs = Table("logs",
+	Schema("audit"),
+	Column("id", Integer{PrimaryKey: true}),
+	Column("message", String{}),
+)
+
 func TestTableSchema(t *testing.T) {
 	// Test table properties
 	assert.Equal(t, "users", users.Name())
@@ -75,7 +81,24 @@ func TestTableSchema(t *testing.T) {
 	// As well as uniques
 	assert.Equal(t, []UniqueConstraint{{"name"}}, users.uniques)
 
+	// Tables can be placed in a schema
+	assert.Equal(t, "", users.Schema())
+	assert.Equal(t, "audit", logs.Schema())
+	assert.Equal(t, "logs", logs.Name())
+
 	// Test improper schemas
+	assert.Panics(t, func() {
+		Table("bad", Schema(""), Column("a", String{}))
+	},
+		"failed to panic when a blank schema was given",
+	)
+
+	assert.Panics(t, func() {
+		Table("bad", Schema("a"), Schema("b"))
+	},
+		"failed to panic when multiple schemas were given",
+	)
+
 	assert.Panics(t, func() {
 		Table("bad", Column("a", String{}), Column("a", String{}))
 	},
@@ -159,3 +182,36 @@ func TestTableUpdate(t *testing.T) {
 		"Jabroni",
 	)
 }
+
+// Test that tables in a schema are qualified in every statement
+func TestTableInSchema(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+
+	expect.SQL(
+		`SELECT "audit"."logs"."id", "audit"."logs"."message" FROM "audit"."logs" WHERE "audit"."logs"."id" = $1`,
+		logs.Select().Where(logs.C["id"].Equals(1)),
+		1,
+	)
+	expect.SQL(
+		`INSERT INTO "audit"."logs" ("message") VALUES ($1)`,
+		Insert(logs.C["message"]).Values(Values{"message": "hello"}),
+		"hello",
+	)
+	expect.SQL(
+		`UPDATE "audit"."logs" SET "message" = $1 WHERE "audit"."logs"."id" = $2`,
+		logs.Update().Values(Values{"message": "hi"}).Where(
+			logs.C["id"].Equals(1),
+		),
+		"hi",
+		1,
+	)
+	expect.SQL(`DELETE FROM "audit"."logs"`, logs.Delete())
+	expect.SQL(`DROP TABLE IF EXISTS "audit"."logs"`, logs.Drop().IfExists())
+	expect.SQL(
+		`CREATE TABLE "audit"."logs" (
+  "id" INTEGER PRIMARY KEY,
+  "message" VARCHAR
+);`,
+		logs.Create(),
+	)
+}
@@ -5,9 +5,11 @@ import "fmt"
 // Text represents TEXT column types.
 type Text struct {
 	NotNull bool
+	Default interface{}
 }
 
 var _ Type = Text{}
+var _ Defaulter = Text{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s Text) Create(d Dialect) (string, error) {
@@ -15,15 +17,27 @@ func (s Text) Create(d Dialect) (string, error) {
 	if s.NotNull {
 		compiled += " NOT NULL"
 	}
+	if HasDefaultValue(s.Default) {
+		def, err := CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		compiled += fmt.Sprintf(" %s", def)
+	}
 	return compiled, nil
 }
 
+// HasDefault implements the Defaulter interface.
+func (s Text) HasDefault() bool {
+	return HasDefaultValue(s.Default)
+}
+
 func (s Text) IsPrimaryKey() bool {
 	return false
 }
 
 func (s Text) IsRequired() bool {
-	return s.NotNull
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s Text) IsUnique() bool {
@@ -12,10 +12,11 @@ type Timestamp struct {
 	PrimaryKey      bool
 	WithTimezone    bool
 	WithoutTimezone bool
-	Default         string // TODO Should this be a clause that compiles?
+	Default         interface{} // A literal value or a Clause such as Now()
 }
 
 var _ Type = Timestamp{}
+var _ Defaulter = Timestamp{}
 
 // Create returns the syntax need to create this column in CREATE statements.
 func (s Timestamp) Create(d Dialect) (string, error) {
@@ -28,18 +29,27 @@ func (s Timestamp) Create(d Dialect) (string, error) {
 	if s.NotNull {
 		compiled += " NOT NULL"
 	}
-	if s.Default != "" {
-		compiled += fmt.Sprintf(" DEFAULT (%s)", s.Default)
+	if HasDefaultValue(s.Default) {
+		def, err := CompileDefault(d, s.Default)
+		if err != nil {
+			return "", err
+		}
+		compiled += fmt.Sprintf(" %s", def)
 	}
 	return compiled, nil
 }
 
+// HasDefault implements the Defaulter interface.
+func (s Timestamp) HasDefault() bool {
+	return HasDefaultValue(s.Default)
+}
+
 func (s Timestamp) IsPrimaryKey() bool {
 	return s.PrimaryKey
 }
 
 func (s Timestamp) IsRequired() bool {
-	return s.NotNull && s.Default == ""
+	return s.NotNull && !s.HasDefault()
 }
 
 func (s Timestamp) IsUnique() bool {
@@ -13,16 +13,38 @@ func TestTimestamp(t *testing.T) {
 
 	expect.Create(
 		"TIMESTAMP DEFAULT (now() at time zone 'utc')",
-		Timestamp{Default: "now() at time zone 'utc'"},
+		Timestamp{Default: Raw("now() at time zone 'utc'")},
 	)
 	expect.Create(
 		"TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT (now() at time zone 'utc')",
 		Timestamp{
 			WithTimezone: true,
 			NotNull:      true,
-			Default:      "now() at time zone 'utc'",
+			Default:      Raw("now() at time zone 'utc'"),
 		},
 	)
+	expect.Create(
+		"TIMESTAMP DEFAULT (CURRENT_TIMESTAMP)",
+		Timestamp{Default: Now()},
+	)
+
+	// Literal defaults will be escaped
+	expect.Create(
+		"TIMESTAMP DEFAULT '2014-01-01 12:00:00+00:00'",
+		Timestamp{Default: time.Date(2014, 1, 1, 12, 0, 0, 0, time.UTC)},
+	)
+	expect.Create(
+		"TIMESTAMP DEFAULT '2014-01-01 12:00:00'",
+		Timestamp{Default: Literal("2014-01-01 12:00:00")},
+	)
+
+	// Strings are literals, never SQL
+	expect.Create(
+		"TIMESTAMP DEFAULT 'now()''); DROP TABLE users; --'",
+		Timestamp{Default: "now()'); DROP TABLE users; --"},
+	)
+	assert.True(Timestamp{NotNull: true}.IsRequired())
+	assert.False(Timestamp{NotNull: true, Default: Now()}.IsRequired())
 
 	d := time.Date(2014, 1, 1, 12, 0, 0, 0, time.UTC)
 	value, err := Timestamp{}.Validate(d)
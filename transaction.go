@@ -0,0 +1,113 @@
+package aspect
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Retrier is an optional interface for dialects that can identify errors
+// after which a transaction may succeed if it is retried, such as
+// serialization failures and deadlocks.
+type Retrier interface {
+	Retryable(error) bool
+}
+
+// TxOptions are the options of transactions started by InTransaction
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+
+	// Retries is the maximum number of times the function will be called
+	// again after an error the dialect identifies as retryable
+	Retries int
+
+	// Backoff returns the delay before the given retry, starting at 1. If
+	// nil, DefaultBackoff is used.
+	Backoff func(retry int) time.Duration
+}
+
+// DefaultBackoff is used by transactions with retries but no Backoff
+var DefaultBackoff = ExponentialBackoff(10*time.Millisecond, time.Second)
+
+// ExponentialBackoff returns a backoff that doubles from the base delay with
+// every retry, up to the max delay.
+func ExponentialBackoff(base, max time.Duration) func(int) time.Duration {
+	return func(retry int) time.Duration {
+		delay := base
+		for i := 1; i < retry && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			return max
+		}
+		return delay
+	}
+}
+
+// InTransaction calls the given function with a new transaction. The
+// transaction is committed if the function returns nil and rolled back if
+// it returns an error or panics, in which case the panic continues after
+// the rollback. The options may be nil.
+//
+// If the options allow retries and the function or commit fails with an
+// error that the dialect identifies as retryable, such as a serialization
+// failure, the whole function is called again with a new transaction. The
+// function should therefore have no side effects outside the transaction.
+//
+//  err := conn.InTransaction(ctx, &aspect.TxOptions{
+//      Isolation: sql.LevelSerializable,
+//      Retries:   3,
+//  }, func(tx aspect.Transaction) error {
+//      _, err := tx.Execute(Accounts.Update().Values(...))
+//      return err
+//  })
+//
+func (db *DB) InTransaction(ctx context.Context, opts *TxOptions, fn func(Transaction) error) error {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	retrier, _ := db.dialect.(Retrier)
+	for retry := 0; ; retry++ {
+		err := db.inTransaction(ctx, opts, fn)
+		if err == nil || retry >= opts.Retries || retrier == nil || !retrier.Retryable(err) {
+			return err
+		}
+
+		backoff := opts.Backoff
+		if backoff == nil {
+			backoff = DefaultBackoff
+		}
+		timer := time.NewTimer(backoff(retry + 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// inTransaction calls the function with a single transaction
+func (db *DB) inTransaction(ctx context.Context, opts *TxOptions, fn func(Transaction) error) error {
+	sqlTx, err := db.conn.BeginTx(ctx, &sql.TxOptions{
+		Isolation: opts.Isolation,
+		ReadOnly:  opts.ReadOnly,
+	})
+	if err != nil {
+		return err
+	}
+	tx := db.wrapTx(sqlTx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
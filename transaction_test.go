@@ -0,0 +1,106 @@
+package aspect
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errConflict = errors.New("conflict")
+
+// retryDialect retries errConflict
+type retryDialect struct {
+	defaultDialect
+}
+
+func (d *retryDialect) Retryable(err error) bool {
+	return err == errConflict
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, backoff(1))
+	assert.Equal(t, 20*time.Millisecond, backoff(2))
+	assert.Equal(t, 40*time.Millisecond, backoff(3))
+	assert.Equal(t, 50*time.Millisecond, backoff(4))
+	assert.Equal(t, 50*time.Millisecond, backoff(100))
+}
+
+func TestDB_InTransaction(t *testing.T) {
+	db, d := openCounting(t, "counting_transactions")
+	defer db.Close()
+	ctx := context.Background()
+
+	// Commit on nil
+	require.Nil(t, db.InTransaction(ctx, nil, func(tx Transaction) error {
+		_, err := tx.Execute(users.Delete())
+		return err
+	}))
+	assert.Equal(t, 1, d.commits)
+	assert.Equal(t, 0, d.rollbacks)
+
+	// Rollback on error
+	err := db.InTransaction(ctx, nil, func(tx Transaction) error {
+		return errConflict
+	})
+	assert.Equal(t, errConflict, err)
+	assert.Equal(t, 1, d.rollbacks)
+
+	// Rollback and panic again on panic
+	assert.Panics(t, func() {
+		db.InTransaction(ctx, nil, func(tx Transaction) error {
+			panic("failure")
+		})
+	})
+	assert.Equal(t, 2, d.rollbacks)
+
+	// Retryable errors are retried with the dialect
+	db.dialect = &retryDialect{}
+	var calls int
+	opts := &TxOptions{
+		Retries: 2,
+		Backoff: func(int) time.Duration { return time.Millisecond },
+	}
+	err = db.InTransaction(ctx, opts, func(tx Transaction) error {
+		calls += 1
+		if calls < 3 {
+			return errConflict
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, d.commits)
+
+	calls = 0
+	err = db.InTransaction(ctx, opts, func(tx Transaction) error {
+		calls += 1
+		return errConflict
+	})
+	assert.Equal(t, errConflict, err)
+	assert.Equal(t, 3, calls)
+
+	// Other errors are not retried
+	calls = 0
+	err = db.InTransaction(ctx, opts, func(tx Transaction) error {
+		calls += 1
+		return errors.New("invalid")
+	})
+	assert.Equal(t, "invalid", err.Error())
+	assert.Equal(t, 1, calls)
+
+	// Retries stop when the context is done
+	cancelled, cancel := context.WithCancel(ctx)
+	calls = 0
+	err = db.InTransaction(cancelled, &TxOptions{Retries: 5}, func(tx Transaction) error {
+		calls += 1
+		cancel()
+		return errConflict
+	})
+	assert.Equal(t, errConflict, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,74 @@
+package aspect
+
+import "fmt"
+
+// TruncateStmt is the internal representation of a TRUNCATE statement.
+type TruncateStmt struct {
+	Stmt
+	tables          []*TableElem
+	restartIdentity bool
+	behavior        string
+}
+
+// Cascade adds the CASCADE modifier to a TRUNCATE statement, which will also
+// truncate any tables with foreign keys that reference the given tables.
+func (stmt TruncateStmt) Cascade() TruncateStmt {
+	stmt.behavior = "CASCADE"
+	return stmt
+}
+
+// RestartIdentity adds the RESTART IDENTITY modifier to a TRUNCATE
+// statement, which will reset any sequences owned by the tables' columns.
+func (stmt TruncateStmt) RestartIdentity() TruncateStmt {
+	stmt.restartIdentity = true
+	return stmt
+}
+
+// Restrict adds the RESTRICT modifier to a TRUNCATE statement, which will
+// refuse to truncate the tables if other tables reference them.
+func (stmt TruncateStmt) Restrict() TruncateStmt {
+	stmt.behavior = "RESTRICT"
+	return stmt
+}
+
+// String outputs the parameter-less TRUNCATE statement in a neutral dialect.
+func (stmt TruncateStmt) String() string {
+	c, _ := stmt.Compile(&defaultDialect{}, Params())
+	return c
+}
+
+// Compile outputs the TRUNCATE statement using the given dialect and
+// parameters. An error may be returned because of a pre-existing error or
+// because an error occurred during compilation.
+func (stmt TruncateStmt) Compile(d Dialect, p *Parameters) (string, error) {
+	if err := stmt.Error(); err != nil {
+		return "", err
+	}
+	compiled := fmt.Sprintf(
+		"TRUNCATE TABLE %s", compileTables(stmt.tables, d, p),
+	)
+	if stmt.restartIdentity {
+		compiled += " RESTART IDENTITY"
+	}
+	if stmt.behavior != "" {
+		compiled += fmt.Sprintf(" %s", stmt.behavior)
+	}
+	return compiled, nil
+}
+
+// Truncate creates a TRUNCATE statement for the given tables. There must be
+// at least one table.
+func Truncate(tables ...*TableElem) (stmt TruncateStmt) {
+	if len(tables) == 0 {
+		stmt.SetError("aspect: TRUNCATE requires at least one table")
+		return
+	}
+	for _, table := range tables {
+		if table == nil {
+			stmt.SetError("aspect: attempting to TRUNCATE a nil table")
+			return
+		}
+	}
+	stmt.tables = tables
+	return
+}
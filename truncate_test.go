@@ -0,0 +1,18 @@
+package aspect
+
+import "testing"
+
+func TestTruncateStmt(t *testing.T) {
+	expect := NewTester(t, &defaultDialect{})
+	expect.SQL(`TRUNCATE TABLE "users"`, users.Truncate())
+	expect.SQL(
+		`TRUNCATE TABLE "users", "edges" RESTART IDENTITY CASCADE`,
+		Truncate(users, edges).RestartIdentity().Cascade(),
+	)
+	expect.SQL(
+		`TRUNCATE TABLE "audit"."logs" RESTRICT`,
+		logs.Truncate().Restrict(),
+	)
+	expect.Error(Truncate())
+	expect.Error(Truncate(nil))
+}
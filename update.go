@@ -15,6 +15,11 @@ func (stmt UpdateStmt) String() string {
 	return compiled
 }
 
+// Table returns the table of this statement
+func (stmt UpdateStmt) Table() *TableElem {
+	return stmt.table
+}
+
 // Compile outputs the UPDATE statement using the given dialect and parameters.
 // An error may be returned because of a pre-existing error or because
 // an error occurred during compilation.
@@ -40,8 +45,8 @@ func (stmt UpdateStmt) Compile(d Dialect, params *Parameters) (string, error) {
 
 	// Begin building the UPDATE statement
 	compiled := fmt.Sprintf(
-		`UPDATE "%s" SET %s`,
-		stmt.table.Name(),
+		`UPDATE %s SET %s`,
+		stmt.table.Compile(d, params),
 		valuesStmt,
 	)
 
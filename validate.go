@@ -0,0 +1,164 @@
+package aspect
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+	bytesType    = reflect.TypeOf([]byte{})
+)
+
+// StructError describes the mismatches between a struct and a table found
+// by ValidateStruct.
+type StructError struct {
+	Table   string
+	Struct  string
+	Columns []string // Columns without a matching field
+	Fields  []string // Fields without a matching column
+	Types   []string // Fields with a type incompatible with their column
+}
+
+// Error implements the error interface
+func (e *StructError) Error() string {
+	var problems []string
+	if len(e.Columns) > 0 {
+		problems = append(problems, fmt.Sprintf(
+			"columns without fields: %s", strings.Join(e.Columns, ", "),
+		))
+	}
+	if len(e.Fields) > 0 {
+		problems = append(problems, fmt.Sprintf(
+			"fields without columns: %s", strings.Join(e.Fields, ", "),
+		))
+	}
+	if len(e.Types) > 0 {
+		problems = append(problems, fmt.Sprintf(
+			"incompatible types: %s", strings.Join(e.Types, ", "),
+		))
+	}
+	return fmt.Sprintf(
+		"aspect: %s does not match the table %s: %s",
+		e.Struct, e.Table, strings.Join(problems, "; "),
+	)
+}
+
+// ValidateStruct confirms that every column of the table has a field in the
+// given struct, which may also be a pointer to a struct, and that every
+// field has a column. Fields qualified with the name of another table, such
+// as `db:"users.id"`, are ignored. Fields of aspect column types must also
+// have a compatible Go type, though interfaces and other sql.Scanner
+// implementations are always accepted. It returns a *StructError if there
+// are any mismatches.
+//
+// It is intended to be called during startup or in tests:
+//
+//  if err := aspect.ValidateStruct(Users, User{}); err != nil {
+//      log.Fatal(err)
+//  }
+//
+func ValidateStruct(table *TableElem, v interface{}) error {
+	if table == nil {
+		return fmt.Errorf("aspect: cannot validate against a nil table")
+	}
+	elem := reflect.TypeOf(v)
+	if elem != nil && elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem == nil || elem.Kind() != reflect.Struct {
+		return fmt.Errorf(
+			"aspect: only structs can be validated, received %T", v,
+		)
+	}
+
+	mismatch := &StructError{Table: table.Name(), Struct: elem.String()}
+	var fields fields
+	for _, field := range SelectFieldsFromElem(elem) {
+		if field.table != "" && field.table != table.Name() {
+			continue
+		}
+		fields = append(fields, field)
+
+		column, ok := table.C[field.column]
+		if !ok {
+			mismatch.Fields = append(mismatch.Fields, field.column)
+			continue
+		}
+		t := elem.FieldByIndex(field.index).Type
+		if !compatible(column.Type(), t) {
+			mismatch.Types = append(mismatch.Types, fmt.Sprintf(
+				"%s (%s)", field.column, t,
+			))
+		}
+	}
+	for _, column := range table.Columns() {
+		if !fields.HasColumn(column.Name()) {
+			mismatch.Columns = append(mismatch.Columns, column.Name())
+		}
+	}
+
+	if len(mismatch.Columns) == 0 && len(mismatch.Fields) == 0 && len(mismatch.Types) == 0 {
+		return nil
+	}
+	return mismatch
+}
+
+// compatible returns true if values of the given column type can be
+// scanned into the Go type. Only aspect types are checked.
+func compatible(typ Type, t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+
+	var null reflect.Type
+	var kinds []reflect.Kind
+	switch typ.(type) {
+	case Integer, BigInt:
+		null = nullInt64Type
+		kinds = []reflect.Kind{
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+			reflect.Uint32, reflect.Uint64,
+		}
+	case Real, Double:
+		null = nullFloat64Type
+		kinds = []reflect.Kind{reflect.Float32, reflect.Float64}
+	case String, Text:
+		if t == bytesType {
+			return true
+		}
+		null = nullStringType
+		kinds = []reflect.Kind{reflect.String}
+	case Boolean:
+		null = nullBoolType
+		kinds = []reflect.Kind{reflect.Bool}
+	case Timestamp, Date:
+		if t == timeType {
+			return true
+		}
+		null = nullTimeType
+	default:
+		return true
+	}
+	if t == null {
+		return true
+	}
+	for _, kind := range kinds {
+		if t.Kind() == kind {
+			return true
+		}
+	}
+
+	// Other scanners may accept any value, but not the sql.Null types
+	switch t {
+	case nullStringType, nullInt64Type, nullFloat64Type, nullBoolType, nullTimeType, timeType:
+		return false
+	}
+	return reflect.PtrTo(t).Implements(scannerType)
+}
@@ -0,0 +1,60 @@
+package aspect
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var validated = Table("validated",
+	Column("id", Integer{NotNull: true}),
+	Column("name", String{}),
+	Column("score", Double{}),
+	Column("active", Boolean{}),
+	Column("created_at", Timestamp{}),
+	Column("data", RawType{Name: "BYTEA"}),
+	PrimaryKey("id"),
+)
+
+type validRow struct {
+	ID        int64          `db:"id"`
+	Name      sql.NullString `db:"name"`
+	Score     *float64       `db:"score"`
+	Active    interface{}    `db:"active"`
+	CreatedAt time.Time      `db:"created_at"`
+	Data      []byte         `db:"data"`
+	Other     string         `db:"others.name"`
+}
+
+type invalidRow struct {
+	ID     string        `db:"id"`
+	Score  sql.NullInt64 `db:"score"`
+	Active bool          `db:"active"`
+	Extra  string        `db:"extra"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	assert.Nil(t, ValidateStruct(validated, validRow{}))
+	assert.Nil(t, ValidateStruct(validated, &validRow{}))
+
+	err := ValidateStruct(validated, invalidRow{})
+	require.NotNil(t, err)
+	mismatch, ok := err.(*StructError)
+	require.True(t, ok)
+	assert.Equal(t, []string{"name", "created_at", "data"}, mismatch.Columns)
+	assert.Equal(t, []string{"extra"}, mismatch.Fields)
+	assert.Equal(t,
+		[]string{"id (string)", "score (sql.NullInt64)"},
+		mismatch.Types,
+	)
+	assert.Equal(t,
+		"aspect: aspect.invalidRow does not match the table validated: columns without fields: name, created_at, data; fields without columns: extra; incompatible types: id (string), score (sql.NullInt64)",
+		err.Error(),
+	)
+
+	assert.NotNil(t, ValidateStruct(nil, validRow{}))
+	assert.NotNil(t, ValidateStruct(validated, 1))
+}